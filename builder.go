@@ -0,0 +1,74 @@
+package structmerge
+
+import "reflect"
+
+// ConfigBuilder builds a Config through chained method calls.
+//
+//	cfg, err := structmerge.NewConfig().
+//		WithOption(structmerge.ExcludeEmpty).
+//		Include("Name", "Address.City").
+//		Build()
+type ConfigBuilder struct {
+	cfg Config
+}
+
+// NewConfig returns a ConfigBuilder starting from the default configuration.
+func NewConfig() *ConfigBuilder {
+	return &ConfigBuilder{cfg: Config{Option: IncludeAll}}
+}
+
+// WithOption sets the merge option.
+func (b *ConfigBuilder) WithOption(option MergeOption) *ConfigBuilder {
+	b.cfg.Option = option
+	return b
+}
+
+// Include adds fields to the include list.
+func (b *ConfigBuilder) Include(fields ...string) *ConfigBuilder {
+	b.cfg.Include = append(b.cfg.Include, fields...)
+	return b
+}
+
+// Exclude adds fields to the exclude list.
+func (b *ConfigBuilder) Exclude(fields ...string) *ConfigBuilder {
+	b.cfg.Exclude = append(b.cfg.Exclude, fields...)
+	return b
+}
+
+// WithTransformer registers a transformer computing the value to assign to
+// the field at path from the source value.
+func (b *ConfigBuilder) WithTransformer(path string, fn func(reflect.Value) reflect.Value) *ConfigBuilder {
+	if b.cfg.Transformers == nil {
+		b.cfg.Transformers = make(map[string]func(dst, src reflect.Value) reflect.Value)
+	}
+	b.cfg.Transformers[path] = func(_, src reflect.Value) reflect.Value {
+		return fn(src)
+	}
+	return b
+}
+
+// Build returns the configured Config. Like ValidateConfig, it reports every
+// included path that is also excluded, or lies within an excluded field, in
+// an ErrorList of *FieldError values wrapping ErrConflictingPaths.
+func (b *ConfigBuilder) Build() (Config, error) {
+	var errs ErrorList
+	for _, path := range b.cfg.Include {
+		if isExcluded(path, b.cfg.Exclude) {
+			errs = append(errs, &FieldError{Path: path, Err: ErrConflictingPaths})
+		}
+	}
+	if len(errs) > 0 {
+		return Config{}, errs
+	}
+
+	cfg := b.cfg
+	cfg.Include = append([]string(nil), b.cfg.Include...)
+	cfg.Exclude = append([]string(nil), b.cfg.Exclude...)
+	if b.cfg.Transformers != nil {
+		cfg.Transformers = make(map[string]func(dst, src reflect.Value) reflect.Value, len(b.cfg.Transformers))
+		for path, fn := range b.cfg.Transformers {
+			cfg.Transformers[path] = fn
+		}
+	}
+	return cfg, nil
+}
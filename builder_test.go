@@ -0,0 +1,83 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigBuilder(t *testing.T) {
+	cfg, err := NewConfig().
+		WithOption(ExcludeEmpty).
+		Include("Name").
+		Include("Address.City", "Address.Street").
+		WithTransformer("Name", func(src reflect.Value) reflect.Value {
+			return reflect.ValueOf(strings.ToUpper(src.String()))
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Option != ExcludeEmpty {
+		t.Errorf("expected ExcludeEmpty, got %v", cfg.Option)
+	}
+	if !reflect.DeepEqual(cfg.Include, []string{"Name", "Address.City", "Address.Street"}) {
+		t.Errorf("unexpected include list %v", cfg.Include)
+	}
+
+	dst := TestStruct{Name: "alice", Age: 30, Address: Address{City: "Kampala"}}
+	src := TestStruct{Name: "bob", Age: 40, Address: Address{Street: "1 Main St"}}
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Name: "BOB", Age: 30, Address: Address{Street: "1 Main St", City: "Kampala"}}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestConfigBuilderDefaults(t *testing.T) {
+	cfg, err := NewConfig().Exclude("Age").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Option != IncludeAll || len(cfg.Include) != 0 {
+		t.Errorf("unexpected config %#v", cfg)
+	}
+}
+
+func TestConfigBuilderConflict(t *testing.T) {
+	// Excluding a field nested in an included one is not a conflict.
+	cfg, err := NewConfig().Include("Name", "Address").Exclude("Age", "Address.City").Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Include, []string{"Name", "Address"}) || !reflect.DeepEqual(cfg.Exclude, []string{"Age", "Address.City"}) {
+		t.Errorf("unexpected config %#v", cfg)
+	}
+
+	_, err = NewConfig().Include("Name", "Address.City").Exclude("Name", "Address").Build()
+	var errs ErrorList
+	if !errors.Is(err, ErrConflictingPaths) || !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 conflicting paths, got %v", err)
+	}
+	for i, path := range []string{"Name", "Address.City"} {
+		var fieldErr *FieldError
+		if !errors.As(errs[i], &fieldErr) || fieldErr.Path != path {
+			t.Errorf("errs[%d] = %v, want a conflict for %s", i, errs[i], path)
+		}
+	}
+}
+
+func TestConfigBuilderBuildIsolated(t *testing.T) {
+	b := NewConfig().Include("Name")
+	cfg, _ := b.Build()
+	b.Include("Age")
+
+	if len(cfg.Include) != 1 {
+		t.Errorf("expected a built config to be unaffected by later builder calls, got %v", cfg.Include)
+	}
+}
@@ -0,0 +1,251 @@
+package structmerge
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// MergeCompatible merges src into dst like Merge, but dst and src may be
+// different struct types. dst must be a pointer to a struct and src a struct
+// or a pointer to one.
+//
+// Fields are matched by name or, when cfg.UseJSONTags is set, by `json` tag
+// name. A matching field is merged if the source type is assignable to the
+// destination type; nested structs of different types are matched field by
+// field in turn. A string source field is parsed into a destination field
+// whose type, or a pointer to it, implements encoding.TextUnmarshaler; an
+// empty string yields the zero value. A numeric source field is converted
+// to a numeric destination field tagged `merge:"coerce"`; values out of the
+// range of the destination type yield a *FieldError wrapping
+// ErrNumericOverflow, and floats lose their fractional part with a warning
+// logged to cfg.Logger. Other matching fields are skipped,
+// unless cfg.Strict is set, in which case a *FieldError wrapping
+// ErrTypeMismatch is returned. cfg.IgnoreTypeMismatch overrides Strict.
+// Fields present in only one of the structs are ignored.
+//
+// Paths in Include and Exclude refer to the fields of dst.
+func MergeCompatible(dst, src interface{}, cfg ...Config) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	srcValue, err := structValue(src)
+	if err != nil {
+		return err
+	}
+
+	config := resolveConfig(dstValue.Elem().Type(), configOf(cfg))
+	state := newMergeState(context.Background())
+	state.filter = newPathFilter(config)
+	return mergeCompatible(state, dstValue.Elem(), srcValue, config, "")
+}
+
+func mergeCompatible(state *mergeState, dst, src reflect.Value, cfg Config, prefix string) error {
+	if err := state.ctx.Err(); err != nil {
+		return err
+	}
+
+	srcMeta := cachedMeta(src.Type())
+	for _, field := range cachedMeta(dst.Type()).fields {
+		if !field.exported {
+			continue
+		}
+		if field.masked {
+			state.logMasked(cfg, prefix+field.name)
+			continue
+		}
+
+		srcFieldMeta, ok := srcMeta.fieldByKey(field.name, false)
+		if !ok && cfg.UseJSONTags && field.json != "" {
+			srcFieldMeta, ok = srcMeta.fieldByKey(field.json, true)
+		}
+		if !ok || !srcFieldMeta.exported {
+			continue
+		}
+
+		path := prefix + field.name
+		if state.filter.skip(path, field.nested || field.ptr) {
+			continue
+		}
+
+		dstField := dst.FieldByIndex(field.index)
+		srcField := src.FieldByIndex(srcFieldMeta.index)
+
+		var err error
+		switch {
+		case dstField.Type() == srcField.Type():
+			// Fields of the same type are merged as by Merge.
+			_, err = mergeStructField(state, dstField, srcField, field, cfg, path)
+
+		case field.nested && isNestedStruct(srcField.Type()):
+			// Structs of different types are matched field by field.
+			if cfg.FieldPredicate != nil && !cfg.FieldPredicate(path, dstField, srcField) {
+				continue
+			}
+			err = mergeCompatible(state, dstField, srcField, cfg, path+".")
+
+		case srcField.Type().AssignableTo(dstField.Type()):
+			if cfg.FieldPredicate != nil && !cfg.FieldPredicate(path, dstField, srcField) {
+				continue
+			}
+			// Store the value as the destination type, e.g. in an interface
+			// field, and merge it as a value of that type.
+			converted := reflect.New(dstField.Type()).Elem()
+			converted.Set(srcField)
+			err = mergeField(state, dstField, converted, field.opts, cfg, path)
+
+		case srcField.Kind() == reflect.String && isTextUnmarshaler(dstField.Type()):
+			if cfg.FieldPredicate != nil && !cfg.FieldPredicate(path, dstField, srcField) {
+				continue
+			}
+			var converted reflect.Value
+			if converted, err = unmarshalText(dstField.Type(), srcField.String()); err != nil {
+				err = &FieldError{Path: path, Err: err}
+				break
+			}
+			err = mergeField(state, dstField, converted, field.opts, cfg, path)
+
+		case field.opts.Contains("coerce") && isNumber(srcField.Kind()) && isNumber(dstField.Kind()):
+			if cfg.FieldPredicate != nil && !cfg.FieldPredicate(path, dstField, srcField) {
+				continue
+			}
+			converted, ok := coerceNumber(srcField, dstField.Type())
+			if !ok {
+				err = &FieldError{Path: path, Err: ErrNumericOverflow}
+				break
+			}
+			if isFloat(srcField.Kind()) && !isFloat(dstField.Kind()) && srcField.Float() != math.Trunc(srcField.Float()) {
+				state.logTruncated(cfg, path, srcField, converted)
+			}
+			err = mergeField(state, dstField, converted, field.opts, cfg, path)
+
+		case cfg.Strict && !cfg.IgnoreTypeMismatch:
+			err = &FieldError{Path: path, Err: ErrTypeMismatch}
+
+		default:
+			state.logSkipped(cfg, path, fmt.Sprintf("type mismatch: %s and %s", srcField.Type(), dstField.Type()))
+		}
+
+		if err != nil {
+			if err := state.fieldError(cfg, path, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if prefix == "" {
+		return state.collected()
+	}
+	return nil
+}
+
+// coerceNumber converts the number v to the numeric type t, truncating
+// floats converted to integers. It returns false if the value is out of the
+// range of t.
+func coerceNumber(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	out := reflect.New(t).Elem()
+	switch {
+	case isFloat(v.Kind()):
+		f := v.Float()
+		switch {
+		case isFloat(t.Kind()):
+			if out.OverflowFloat(f) {
+				return reflect.Value{}, false
+			}
+			out.SetFloat(f)
+		case math.IsNaN(f):
+			return reflect.Value{}, false
+		case isUnsigned(t.Kind()):
+			f = math.Trunc(f)
+			if f < 0 || f >= 1<<64 || out.OverflowUint(uint64(f)) {
+				return reflect.Value{}, false
+			}
+			out.SetUint(uint64(f))
+		default:
+			f = math.Trunc(f)
+			if f < -(1<<63) || f >= 1<<63 || out.OverflowInt(int64(f)) {
+				return reflect.Value{}, false
+			}
+			out.SetInt(int64(f))
+		}
+	case isUnsigned(v.Kind()):
+		u := v.Uint()
+		switch {
+		case isFloat(t.Kind()):
+			out.SetFloat(float64(u))
+		case isUnsigned(t.Kind()):
+			if out.OverflowUint(u) {
+				return reflect.Value{}, false
+			}
+			out.SetUint(u)
+		default:
+			if u > math.MaxInt64 || out.OverflowInt(int64(u)) {
+				return reflect.Value{}, false
+			}
+			out.SetInt(int64(u))
+		}
+	default:
+		n := v.Int()
+		switch {
+		case isFloat(t.Kind()):
+			out.SetFloat(float64(n))
+		case isUnsigned(t.Kind()):
+			if n < 0 || out.OverflowUint(uint64(n)) {
+				return reflect.Value{}, false
+			}
+			out.SetUint(uint64(n))
+		default:
+			if out.OverflowInt(n) {
+				return reflect.Value{}, false
+			}
+			out.SetInt(n)
+		}
+	}
+	return out, true
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isUnsigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isTextUnmarshaler reports whether values of type t can be parsed from text,
+// either because a pointer to t implements encoding.TextUnmarshaler or
+// because t is such a pointer.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType) ||
+		t.Kind() == reflect.Ptr && t.Implements(textUnmarshalerType)
+}
+
+// unmarshalText returns a value of type t parsed from text. An empty text
+// yields the zero value of t.
+func unmarshalText(t reflect.Type, text string) (reflect.Value, error) {
+	if text == "" {
+		return reflect.Zero(t), nil
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+
+	v := reflect.New(t.Elem())
+	if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
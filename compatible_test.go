@@ -0,0 +1,339 @@
+package structmerge
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// pbAddress and pbUser stand in for generated protobuf messages.
+type pbAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type pbUser struct {
+	Name        string     `json:"name"`
+	Age         int32      `json:"age"`
+	Address     *pbAddress `json:"address"`
+	Home        pbAddress  `json:"home"`
+	EmailAddr   string     `json:"email"`
+	Description string     `json:"description"`
+}
+
+type domainUser struct {
+	Name    string
+	Age     int
+	Address *pbAddress
+	Home    Address
+	Email   string `json:"email"`
+	Extra   interface{}
+}
+
+func TestMergeCompatible(t *testing.T) {
+	src := pbUser{
+		Name:      "Bob",
+		Age:       40,
+		Address:   &pbAddress{Street: "Main St"},
+		Home:      pbAddress{City: "Gulu"},
+		EmailAddr: "b@x.io",
+	}
+
+	tests := []struct {
+		name     string
+		cfg      []Config
+		expected domainUser
+	}{
+		{
+			name: "Matching names",
+			expected: domainUser{
+				Name:    "Bob",
+				Age:     30,
+				Address: &pbAddress{Street: "Main St"},
+				Home:    Address{Street: "", City: "Gulu", Country: "Uganda"},
+				Email:   "a@x.io",
+			},
+		},
+		{
+			name: "JSON tags",
+			cfg:  []Config{{Option: ExcludeEmpty, UseJSONTags: true}},
+			expected: domainUser{
+				Name:    "Bob",
+				Age:     30,
+				Address: &pbAddress{Street: "Main St"},
+				Home:    Address{Street: "Old St", City: "Gulu", Country: "Uganda"},
+				Email:   "b@x.io",
+			},
+		},
+		{
+			name: "Include",
+			cfg:  []Config{{Include: []string{"Home.City"}}},
+			expected: domainUser{
+				Name:  "Alice",
+				Age:   30,
+				Home:  Address{Street: "Old St", City: "Gulu", Country: "Uganda"},
+				Email: "a@x.io",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := domainUser{
+				Name:  "Alice",
+				Age:   30,
+				Home:  Address{Street: "Old St", City: "Kampala", Country: "Uganda"},
+				Email: "a@x.io",
+			}
+			if err := MergeCompatible(&dst, src, tt.cfg...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.expected) {
+				t.Errorf("MergeCompatible() = %+v, want %+v", dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeCompatibleAssignable(t *testing.T) {
+	type source struct{ Extra string }
+
+	var dst domainUser
+	if err := MergeCompatible(&dst, &source{Extra: "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Extra != "x" {
+		t.Errorf("Extra = %v, want x", dst.Extra)
+	}
+}
+
+// IPAddr parses itself from its dotted text form.
+type IPAddr []byte
+
+func (ip *IPAddr) UnmarshalText(text []byte) error {
+	var a, b, c, d byte
+	if _, err := fmt.Sscanf(string(text), "%d.%d.%d.%d", &a, &b, &c, &d); err != nil {
+		return err
+	}
+	*ip = IPAddr{a, b, c, d}
+	return nil
+}
+
+func TestMergeCompatibleTextUnmarshaler(t *testing.T) {
+	type hostDTO struct {
+		Name    string
+		Addr    string
+		Gateway string
+		Created string
+	}
+	type host struct {
+		Name    string
+		Addr    IPAddr
+		Gateway *IPAddr
+		Created time.Time
+	}
+
+	tests := []struct {
+		name     string
+		dst      host
+		src      hostDTO
+		cfg      Config
+		expected host
+	}{
+		{
+			name: "Parses text",
+			src:  hostDTO{Name: "web", Addr: "10.0.0.1", Gateway: "10.0.0.254", Created: "2024-01-02T03:04:05Z"},
+			expected: host{
+				Name:    "web",
+				Addr:    IPAddr{10, 0, 0, 1},
+				Gateway: &IPAddr{10, 0, 0, 254},
+				Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		},
+		{
+			name:     "Empty text is zero",
+			dst:      host{Addr: IPAddr{10, 0, 0, 1}, Gateway: &IPAddr{10, 0, 0, 254}},
+			src:      hostDTO{Name: "web"},
+			expected: host{Name: "web"},
+		},
+		{
+			name:     "Exclude empty",
+			dst:      host{Addr: IPAddr{10, 0, 0, 1}},
+			src:      hostDTO{Gateway: "10.0.0.254"},
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: host{Addr: IPAddr{10, 0, 0, 1}, Gateway: &IPAddr{10, 0, 0, 254}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := tt.dst
+			if err := MergeCompatible(&dst, tt.src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, dst)
+			}
+		})
+	}
+
+	var dst host
+	err := MergeCompatible(&dst, hostDTO{Addr: "localhost"})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Addr" {
+		t.Errorf("expected a *FieldError for Addr, got %v", err)
+	}
+}
+
+func TestMergeCompatibleStrict(t *testing.T) {
+	var dst domainUser
+	err := MergeCompatible(&dst, pbUser{Age: 40}, Config{Strict: true})
+
+	var fieldErr *FieldError
+	if !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &fieldErr) || fieldErr.Path != "Age" {
+		t.Errorf("expected ErrTypeMismatch for Age, got %v", err)
+	}
+}
+
+func TestMergeCompatibleIgnoreTypeMismatch(t *testing.T) {
+	h := &recordHandler{level: slog.LevelDebug}
+	dst := domainUser{Age: 30}
+	cfg := Config{Strict: true, IgnoreTypeMismatch: true, Include: []string{"Name", "Age"}, Logger: slog.New(h)}
+	if err := MergeCompatible(&dst, pbUser{Name: "Bob", Age: 40}, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dst.Name != "Bob" || dst.Age != 30 {
+		t.Errorf("unexpected result %+v", dst)
+	}
+
+	expected := map[string]string{
+		"level": "DEBUG", "path": "Age", "action": "skip", "option": "IncludeAll",
+		"reason": "type mismatch: int32 and int",
+	}
+	if len(h.records) != 2 || !reflect.DeepEqual(h.records[1], expected) {
+		t.Errorf("records = %v, want %v last", h.records, expected)
+	}
+}
+
+func TestMergeCompatibleLogsSkipped(t *testing.T) {
+	h := &recordHandler{level: slog.LevelDebug}
+	dst := domainUser{Age: 30}
+	if err := MergeCompatible(&dst, pbUser{Age: 40}, Config{Include: []string{"Age"}, Logger: slog.New(h)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dst.Age != 30 {
+		t.Errorf("Age = %d, want 30", dst.Age)
+	}
+
+	expected := map[string]string{
+		"level": "DEBUG", "path": "Age", "action": "skip", "option": "IncludeAll",
+		"reason": "type mismatch: int32 and int",
+	}
+	if len(h.records) != 1 || !reflect.DeepEqual(h.records[0], expected) {
+		t.Errorf("records = %v, want [%v]", h.records, expected)
+	}
+}
+
+type wireMetrics struct {
+	Count  int32
+	Delta  int64
+	Ratio  float64
+	Bytes  uint32
+	Ignore int32
+}
+
+type metrics struct {
+	Count  int64   `merge:"coerce"`
+	Delta  uint16  `merge:"coerce"`
+	Ratio  int     `merge:"coerce"`
+	Bytes  float32 `merge:"coerce"`
+	Ignore int64
+}
+
+func TestMergeCompatibleCoerce(t *testing.T) {
+	h := &recordHandler{level: slog.LevelWarn}
+	dst := metrics{Ignore: 7}
+	src := wireMetrics{Count: 42, Delta: 300, Ratio: 2.75, Bytes: 1024, Ignore: 9}
+	if err := MergeCompatible(&dst, src, Config{Logger: slog.New(h)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := metrics{Count: 42, Delta: 300, Ratio: 2, Bytes: 1024, Ignore: 7}
+	if dst != expected {
+		t.Errorf("got %+v, want %+v", dst, expected)
+	}
+	warning := map[string]string{"level": "WARN", "path": "Ratio", "old": "2.75", "new": "2"}
+	if len(h.records) != 1 || !reflect.DeepEqual(h.records[0], warning) {
+		t.Errorf("records = %v, want [%v]", h.records, warning)
+	}
+
+	for _, tt := range []struct {
+		src  wireMetrics
+		path string
+	}{
+		{wireMetrics{Delta: -1}, "Delta"},
+		{wireMetrics{Delta: 70000}, "Delta"},
+		{wireMetrics{Ratio: 1e300}, "Ratio"},
+	} {
+		err := MergeCompatible(&metrics{}, tt.src)
+		var fieldErr *FieldError
+		if !errors.Is(err, ErrNumericOverflow) || !errors.As(err, &fieldErr) || fieldErr.Path != tt.path {
+			t.Errorf("MergeCompatible(%+v) = %v, want ErrNumericOverflow for %s", tt.src, err, tt.path)
+		}
+	}
+}
+
+func TestMergeCompatibleErrors(t *testing.T) {
+	var dst domainUser
+	if err := MergeCompatible(dst, pbUser{}); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+	if err := MergeCompatible(&dst, 42); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
+
+type compatInner struct {
+	Street, City string
+}
+
+type compatDst struct {
+	Name string
+	Addr compatInner
+}
+
+type compatSrc struct {
+	Name  string
+	Addr  compatInner
+	Extra int
+}
+
+func TestMergeCompatibleNestedPaths(t *testing.T) {
+	src := compatSrc{Name: "Bob", Addr: compatInner{Street: "New St", City: "Gulu"}}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected compatDst
+	}{
+		{"exclude", Config{Exclude: []string{"Addr.City"}}, compatDst{Name: "Bob", Addr: compatInner{Street: "New St", City: "Kampala"}}},
+		{"include", Config{Include: []string{"Addr.Street"}}, compatDst{Name: "Alice", Addr: compatInner{Street: "New St", City: "Kampala"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := compatDst{Name: "Alice", Addr: compatInner{Street: "Old St", City: "Kampala"}}
+			if err := MergeCompatible(&dst, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst != tt.expected {
+				t.Errorf("got %+v, want %+v", dst, tt.expected)
+			}
+		})
+	}
+}
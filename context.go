@@ -0,0 +1,32 @@
+package structmerge
+
+import (
+	"context"
+	"reflect"
+)
+
+// configKey is the context key under which WithConfig stores a Config.
+type configKey struct{}
+
+// WithConfig returns a copy of ctx carrying cfg, for MergeCtx to use. It
+// lets middleware set a request-scoped configuration, such as role-based
+// Include or Exclude paths, without threading it through every call.
+func WithConfig(ctx context.Context, cfg Config) context.Context {
+	return context.WithValue(ctx, configKey{}, cfg)
+}
+
+// ConfigFromContext returns the Config stored in ctx by WithConfig, if any.
+func ConfigFromContext(ctx context.Context) (Config, bool) {
+	cfg, ok := ctx.Value(configKey{}).(Config)
+	return cfg, ok
+}
+
+// MergeCtx is like MergeWithContext but takes its configuration from ctx,
+// as stored by WithConfig. Without one it merges with IncludeAll.
+func MergeCtx(ctx context.Context, dst, src interface{}) error {
+	cfg, ok := ConfigFromContext(ctx)
+	if !ok {
+		cfg = Config{Option: IncludeAll}
+	}
+	return mergeValues(newMergeState(ctx), reflect.ValueOf(dst), reflect.ValueOf(src), cfg, "")
+}
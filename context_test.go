@@ -0,0 +1,40 @@
+package structmerge
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMergeCtx(t *testing.T) {
+	src := TestStruct{Name: "Bob", Age: 0, Count: 5}
+
+	// Without a Config in the context every field is merged.
+	dst := TestStruct{Name: "Alice", Age: 30}
+	if err := MergeCtx(context.Background(), &dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (TestStruct{Name: "Bob", Count: 5}); dst != expected {
+		t.Errorf("got %+v, want %+v", dst, expected)
+	}
+
+	// A request-scoped Config restricts the merge.
+	ctx := WithConfig(context.Background(), Config{Option: ExcludeEmpty, Exclude: []string{"Count"}})
+	if _, ok := ConfigFromContext(ctx); !ok {
+		t.Fatal("expected a Config in the context")
+	}
+	dst = TestStruct{Name: "Alice", Age: 30}
+	if err := MergeCtx(ctx, &dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (TestStruct{Name: "Bob", Age: 30}); dst != expected {
+		t.Errorf("got %+v, want %+v", dst, expected)
+	}
+
+	// The context still cancels the merge.
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := MergeCtx(ctx, &dst, src); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
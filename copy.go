@@ -0,0 +1,251 @@
+package structmerge
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// DeepCopy returns a deep copy of src, which must be a struct or a pointer to
+// a struct. The copy has the same type as src.
+//
+// Pointer fields of the copy point to new allocations rather than to the
+// values referenced by src, and slices, maps and interfaces hold copies of
+// their elements. Only masked fields (see Masker) and unexported fields
+// still share their storage with src. A src whose pointers form a cycle
+// yields ErrCyclicReference.
+func DeepCopy(src interface{}) (interface{}, error) {
+	v := reflect.ValueOf(src)
+
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		if v.IsNil() {
+			return nil, ErrInvalidSource
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, ErrInvalidSource
+	}
+
+	// Start from a shallow copy so that unexported state and masked fields
+	// are preserved.
+	dst := reflect.New(v.Type())
+	dst.Elem().Set(v)
+	cfg := Config{Option: IncludeAll, DeepCopyPointers: true}
+	state := newMergeState(context.Background())
+	state.deepCopy = true
+	if err := mergeValues(state, dst, v, cfg, ""); err != nil {
+		return nil, err
+	}
+
+	if isPtr {
+		return dst.Interface(), nil
+	}
+	return dst.Elem().Interface(), nil
+}
+
+// Revert restores dst, a pointer to a struct, to snapshot, a struct of the
+// same type taken before a merge, typically with Clone:
+//
+//	snap := structmerge.Clone(cfg)
+//	_ = structmerge.Merge(&cfg, overrides)
+//	// ...
+//	err := structmerge.Revert(&cfg, snap)
+//
+// snapshot must be passed by value: a pointer yields ErrInvalidSource, since
+// it usually means that the live struct was passed instead of a copy.
+//
+// Every field merged by Merge is restored, and pointer, slice and map fields
+// are copied as by DeepCopy so that dst does not share them with snapshot. Unexported, masked and
+// `merge:"-"` fields are never merged and are left as they are.
+func Revert(dst, snapshot interface{}) error {
+	src := reflect.ValueOf(snapshot)
+	if src.Kind() != reflect.Struct {
+		return ErrInvalidSource
+	}
+
+	cfg := Config{Option: IncludeAll, DeepCopyPointers: true}
+	state := newMergeState(context.Background())
+	state.deepCopy = true
+	return mergeValues(state, reflect.ValueOf(dst), src, cfg, "")
+}
+
+// copyPointer sets dst to a newly allocated copy of the value src points to.
+// Nested structs are copied with mergeValues so that their own pointer fields
+// are copied as well. A pointer that leads back to one of the values being
+// copied yields ErrCyclicReference.
+func copyPointer(state *mergeState, dst, src reflect.Value, cfg Config, path string) error {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	leave, err := state.enter(src.Pointer(), path)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	ptr := reflect.New(src.Type().Elem())
+
+	// Start from a shallow copy so that unexported state is preserved.
+	ptr.Elem().Set(src.Elem())
+
+	switch src.Elem().Kind() {
+	case reflect.Struct:
+		if err := copyStruct(state, ptr, src.Elem(), path); err != nil {
+			return err
+		}
+	case reflect.Ptr:
+		if err := copyPointer(state, ptr.Elem(), src.Elem(), cfg, path); err != nil {
+			return err
+		}
+	default:
+		if state.deepCopy {
+			if err := copyValue(state, ptr.Elem(), src.Elem(), cfg, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	dst.Set(ptr)
+	return nil
+}
+
+// copyStruct copies every field of the struct src into the struct ptr points
+// to, whatever the settings of the outer merge.
+func copyStruct(state *mergeState, ptr, src reflect.Value, path string) error {
+	copyCfg := Config{Option: IncludeAll, DeepCopyPointers: true}
+	filter := state.filter
+	state.filter = pathFilter{}
+	err := mergeValues(state, ptr, src, copyCfg, path+".")
+	state.filter = filter
+	return err
+}
+
+// copyValue sets dst to a deep copy of src for DeepCopy: slices and maps get
+// new storage, and the elements of slices, arrays and maps, as well as the
+// values held in interfaces, are copied in turn. A slice or map that leads
+// back to one of the values being copied yields ErrCyclicReference.
+func copyValue(state *mergeState, dst, src reflect.Value, cfg Config, path string) error {
+	switch src.Kind() {
+	case reflect.Ptr:
+		return copyPointer(state, dst, src, cfg, path)
+
+	case reflect.Struct:
+		ptr := reflect.New(src.Type())
+		ptr.Elem().Set(src)
+		if err := copyStruct(state, ptr, src, path); err != nil {
+			return err
+		}
+		dst.Set(ptr.Elem())
+
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		if err := copyValue(state, elem, src.Elem(), cfg, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+
+	case reflect.Array:
+		dst.Set(src)
+		if isScalar(src.Type().Elem().Kind()) {
+			return nil
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := copyValue(state, dst.Index(i), src.Index(i), cfg, elementPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		if src.IsNil() || src.Len() == 0 {
+			dst.Set(src)
+			return nil
+		}
+		leave, err := state.enter(src.Pointer(), path)
+		if err != nil {
+			return err
+		}
+		defer leave()
+
+		cp := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		if isScalar(src.Type().Elem().Kind()) {
+			reflect.Copy(cp, src)
+		} else {
+			for i := 0; i < src.Len(); i++ {
+				if err := copyValue(state, cp.Index(i), src.Index(i), cfg, elementPath(path, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+		}
+		dst.Set(cp)
+
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(src)
+			return nil
+		}
+		leave, err := state.enter(src.Pointer(), path)
+		if err != nil {
+			return err
+		}
+		defer leave()
+
+		cp := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(src.Type().Elem()).Elem()
+			if err := copyValue(state, elem, iter.Value(), cfg, elementPath(path, fmt.Sprint(iter.Key()))); err != nil {
+				return err
+			}
+			cp.SetMapIndex(iter.Key(), elem)
+		}
+		dst.Set(cp)
+
+	default:
+		dst.Set(src)
+	}
+	return nil
+}
+
+// enter records that the value at addr is being copied, returning a function
+// that removes the record again. A value already being copied yields
+// ErrCyclicReference.
+func (s *mergeState) enter(addr uintptr, path string) (func(), error) {
+	if s.visited[addr] {
+		return nil, &FieldError{Path: path, Err: ErrCyclicReference}
+	}
+	if s.visited == nil {
+		s.visited = make(map[uintptr]bool)
+	}
+	s.visited[addr] = true
+	return func() { delete(s.visited, addr) }, nil
+}
+
+// isScalar reports whether values of kind k hold no references, so that
+// copying them copies all their contents.
+func isScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String, reflect.Uintptr, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return isNumber(k)
+}
+
+// isContainer reports whether values of kind k hold other values that
+// copyValue copies.
+func isContainer(k reflect.Kind) bool {
+	switch k {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Interface:
+		return true
+	}
+	return false
+}
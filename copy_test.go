@@ -0,0 +1,269 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Team struct {
+	Name    string
+	Lead    *Person
+	Members []string
+	Budget  *int
+	Founded time.Time
+}
+
+func TestDeepCopy(t *testing.T) {
+	budget := 100
+	src := Team{
+		Name: "Core",
+		Lead: &Person{
+			Name:    "Alice",
+			Age:     30,
+			Address: &Address{Street: "123 Main St", City: "Kampala"},
+		},
+		Members: []string{"Bob", "Carol"},
+		Budget:  &budget,
+		Founded: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	out, err := DeepCopy(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cp, ok := out.(Team)
+	if !ok {
+		t.Fatalf("expected Team, got %T", out)
+	}
+
+	if !reflect.DeepEqual(cp, src) {
+		t.Fatalf("expected %#v, got %#v", src, cp)
+	}
+
+	if cp.Lead == src.Lead {
+		t.Error("expected Lead to point to a new allocation")
+	}
+	if cp.Lead.Address == src.Lead.Address {
+		t.Error("expected Lead.Address to point to a new allocation")
+	}
+	if cp.Budget == src.Budget {
+		t.Error("expected Budget to point to a new allocation")
+	}
+
+	// Mutating the copy must not affect the original.
+	cp.Lead.Address.City = "Entebbe"
+	*cp.Budget = 0
+	if src.Lead.Address.City != "Kampala" || budget != 100 {
+		t.Error("mutating the copy changed the original")
+	}
+}
+
+func TestDeepCopyPointer(t *testing.T) {
+	src := &Person{Name: "Alice", Address: &Address{City: "Kampala"}}
+
+	out, err := DeepCopy(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cp, ok := out.(*Person)
+	if !ok {
+		t.Fatalf("expected *Person, got %T", out)
+	}
+	if cp == src || cp.Address == src.Address {
+		t.Error("expected new allocations")
+	}
+	if !reflect.DeepEqual(cp, src) {
+		t.Errorf("expected %#v, got %#v", src, cp)
+	}
+}
+
+func TestDeepCopyInvalidSource(t *testing.T) {
+	for _, src := range []interface{}{10, (*Person)(nil), nil} {
+		if _, err := DeepCopy(src); err != ErrInvalidSource {
+			t.Errorf("DeepCopy(%#v): expected ErrInvalidSource, got %v", src, err)
+		}
+	}
+}
+
+type Node struct {
+	Name string
+	Next *Node
+}
+
+type Pair struct {
+	Left  *Node
+	Right *Node
+}
+
+func TestDeepCopyCycle(t *testing.T) {
+	n := &Node{Name: "self"}
+	n.Next = n
+
+	_, err := DeepCopy(Pair{Left: n})
+	if !errors.Is(err, ErrCyclicReference) {
+		t.Fatalf("expected ErrCyclicReference, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Left.Next") {
+		t.Errorf("expected the error to name the field path, got %q", err)
+	}
+
+	// Merge reports the cycle as well when copying pointers.
+	var dst Pair
+	err = Merge(&dst, Pair{Right: &Node{Name: "a", Next: n}}, Config{DeepCopyPointers: true})
+	if !errors.Is(err, ErrCyclicReference) {
+		t.Fatalf("expected ErrCyclicReference, got %v", err)
+	}
+}
+
+type Inventory struct {
+	Tags   []string
+	Counts map[string]int
+	Items  []*Address
+	Nested map[string][]int
+	Extra  interface{}
+	Grid   [2][]int
+}
+
+func TestDeepCopyContainers(t *testing.T) {
+	orig := Inventory{
+		Tags:   []string{"a", "b"},
+		Counts: map[string]int{"k": 1},
+		Items:  []*Address{{City: "Kampala"}},
+		Nested: map[string][]int{"n": {1, 2}},
+		Extra:  map[string]interface{}{"x": []string{"y"}},
+		Grid:   [2][]int{{1}, {2}},
+	}
+
+	c := Clone(orig)
+	if !reflect.DeepEqual(c, orig) {
+		t.Fatalf("Clone() = %+v, want %+v", c, orig)
+	}
+
+	// Mutating the copy must not affect the original.
+	c.Tags[0] = "changed"
+	c.Counts["k"] = 99
+	c.Items[0].City = "Gulu"
+	c.Nested["n"][0] = 99
+	c.Extra.(map[string]interface{})["x"].([]string)[0] = "z"
+	c.Grid[0][0] = 99
+
+	expected := Inventory{
+		Tags:   []string{"a", "b"},
+		Counts: map[string]int{"k": 1},
+		Items:  []*Address{{City: "Kampala"}},
+		Nested: map[string][]int{"n": {1, 2}},
+		Extra:  map[string]interface{}{"x": []string{"y"}},
+		Grid:   [2][]int{{1}, {2}},
+	}
+	if !reflect.DeepEqual(orig, expected) {
+		t.Errorf("mutating the copy changed the original: %+v", orig)
+	}
+}
+
+func TestDeepCopySliceCycle(t *testing.T) {
+	loop := []interface{}{nil}
+	loop[0] = loop
+
+	_, err := DeepCopy(Inventory{Extra: loop})
+	if !errors.Is(err, ErrCyclicReference) {
+		t.Fatalf("expected ErrCyclicReference, got %v", err)
+	}
+}
+
+func TestDeepCopySharedPointer(t *testing.T) {
+	// The same pointer reached twice without a cycle is not an error.
+	shared := &Node{Name: "shared"}
+
+	out, err := DeepCopy(Pair{Left: shared, Right: shared})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cp := out.(Pair)
+	if cp.Left.Name != "shared" || cp.Right.Name != "shared" {
+		t.Errorf("unexpected copy: %#v", cp)
+	}
+}
+
+func TestMergeDeepCopyPointers(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"IncludeAll", Config{DeepCopyPointers: true}},
+		{"OverwriteEmpty", Config{Option: OverwriteEmpty, DeepCopyPointers: true}},
+		{"Include", Config{Include: []string{"Lead"}, DeepCopyPointers: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Team{Lead: &Person{Name: "Alice", Address: &Address{City: "Kampala"}}}
+
+			var dst Team
+			if err := Merge(&dst, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst.Lead, src.Lead) {
+				t.Fatalf("Lead = %+v, want %+v", dst.Lead, src.Lead)
+			}
+
+			dst.Lead.Name = "Bob"
+			dst.Lead.Address.City = "Gulu"
+			if src.Lead.Name != "Alice" || src.Lead.Address.City != "Kampala" {
+				t.Errorf("mutating dst changed src: %+v %+v", src.Lead, src.Lead.Address)
+			}
+		})
+	}
+
+	// Without DeepCopyPointers the pointer is shared.
+	src := Team{Lead: &Person{Name: "Alice"}}
+	var dst Team
+	if err := Merge(&dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Lead != src.Lead {
+		t.Error("expected the pointer to be shared by default")
+	}
+}
+
+func TestRevert(t *testing.T) {
+	budget := 100
+	team := Team{Name: "Core", Lead: &Person{Name: "Alice"}, Budget: &budget}
+	snap := Clone(team)
+
+	more := 200
+	if err := Merge(&team, Team{Name: "Platform", Lead: &Person{Name: "Bob"}, Budget: &more, Members: []string{"Carol"}}, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := Revert(&team, snap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(team, snap) {
+		t.Errorf("Revert() = %+v, want %+v", team, snap)
+	}
+	if team.Lead == snap.Lead || team.Budget == snap.Budget {
+		t.Error("expected dst not to share pointers with the snapshot")
+	}
+
+	snap.Members = []string{"Dave"}
+	if err := Revert(&team, snap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	team.Members[0] = "Eve"
+	if snap.Members[0] != "Dave" {
+		t.Error("expected dst not to share slices with the snapshot")
+	}
+
+	if err := Revert(&team, &snap); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource for a pointer snapshot, got %v", err)
+	}
+	if err := Revert(team, snap); err != ErrInvalidDestination {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
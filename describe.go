@@ -0,0 +1,64 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// FieldDescription describes a field as listed by Describe.
+type FieldDescription struct {
+	Path string `json:"path"`           // the path used in Include and Exclude
+	Type string `json:"type"`           // the Go type of the field
+	Tag  string `json:"tag,omitempty"`  // the value of the `merge` tag
+	JSON string `json:"json,omitempty"` // the name given by the `json` tag
+}
+
+// Describe returns an indented JSON array describing the fields of v, which
+// may be a struct, a pointer to a struct or a reflect.Type of either, to
+// help writing merge configurations for unfamiliar types. Every field that
+// takes part in a merge is listed in declaration order with its path, Go
+// type, `merge` tag and `json` name; nested structs are listed before their
+// own fields. Describe returns an empty string if v is not a struct.
+func Describe(v interface{}) string {
+	t, err := typeOf(v)
+	if err != nil {
+		return ""
+	}
+
+	fields := []FieldDescription{}
+	describeFields(t, "", map[reflect.Type]bool{t: true}, &fields)
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func describeFields(t reflect.Type, prefix string, visiting map[reflect.Type]bool, fields *[]FieldDescription) {
+	for _, field := range cachedMeta(t).fields {
+		if !field.exported {
+			continue
+		}
+
+		sf := t.FieldByIndex(field.index)
+		path := prefix + field.name
+		*fields = append(*fields, FieldDescription{
+			Path: path,
+			Type: sf.Type.String(),
+			Tag:  string(field.opts),
+			JSON: field.json,
+		})
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if !isNestedStruct(ft) || visiting[ft] {
+			continue
+		}
+
+		visiting[ft] = true
+		describeFields(ft, path+".", visiting, fields)
+		delete(visiting, ft)
+	}
+}
@@ -0,0 +1,40 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type DescribedNode struct {
+	ID     string   `json:"id" merge:"key"`
+	Labels []string `merge:"append"`
+	Owner  Billing
+	Parent *DescribedNode
+	secret string
+}
+
+func TestDescribe(t *testing.T) {
+	out := Describe(&DescribedNode{})
+
+	var got []FieldDescription
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Describe() is not valid JSON: %v\n%s", err, out)
+	}
+
+	expected := []FieldDescription{
+		{Path: "ID", Type: "string", Tag: "key", JSON: "id"},
+		{Path: "Labels", Type: "[]string", Tag: "append"},
+		{Path: "Owner", Type: "structmerge.Billing"},
+		{Path: "Owner.Card", Type: "string", Tag: "group=pii"},
+		{Path: "Owner.Amount", Type: "int"},
+		{Path: "Parent", Type: "*structmerge.DescribedNode"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Describe() =\n%s\nwant %+v", out, expected)
+	}
+
+	if out := Describe(42); out != "" {
+		t.Errorf("Describe(42) = %q, want empty", out)
+	}
+}
@@ -0,0 +1,137 @@
+package structmerge
+
+import (
+	"reflect"
+	"time"
+)
+
+// Diff returns the dot-separated paths of every field whose value differs
+// between a and b. Both must be structs (or pointers to structs) of the same
+// type. The paths use the same notation as Config.Include, so they can be
+// fed straight back into a selective merge.
+//
+// Nested structs and non-nil pointers to structs are compared field by field.
+// time.Time values, also when held in interfaces, are compared with
+// time.Time.Equal, and Merger
+// implementations as a whole with reflect.DeepEqual. Unexported fields and
+// fields tagged with `merge:"-"` are ignored. Pointers that lead back to
+// values already being compared are not followed again; pointers shared by
+// several fields are compared under each of them.
+func Diff(a, b interface{}) ([]string, error) {
+	av, bv, err := structValues(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	d := differ{visited: make(map[[2]uintptr]bool)}
+	d.diffStruct(av, bv, "")
+	return d.paths, nil
+}
+
+// Equal reports whether a and b hold the same values, comparing them as Diff
+// does: a and b are equal exactly when Diff reports no differences. Both must
+// be structs (or pointers to structs) of the same type.
+func Equal(a, b interface{}) (bool, error) {
+	av, bv, err := structValues(a, b)
+	if err != nil {
+		return false, err
+	}
+
+	d := differ{visited: make(map[[2]uintptr]bool), first: true}
+	d.diffStruct(av, bv, "")
+	return len(d.paths) == 0, nil
+}
+
+// structValues returns the structs held in a and b, which must be of the
+// same type.
+func structValues(a, b interface{}) (reflect.Value, reflect.Value, error) {
+	av, err := structValue(a)
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+
+	bv, err := structValue(b)
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+
+	if av.Type() != bv.Type() {
+		return reflect.Value{}, reflect.Value{}, ErrTypeMismatch
+	}
+	return av, bv, nil
+}
+
+// structValue returns the struct held in v, dereferencing a pointer.
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrInvalidSource
+	}
+	return rv, nil
+}
+
+// differ collects the paths of the fields that differ between two values.
+type differ struct {
+	paths   []string
+	visited map[[2]uintptr]bool // pairs of pointers being compared
+	first   bool                // stop at the first difference
+}
+
+func (d *differ) diffStruct(a, b reflect.Value, prefix string) {
+	for _, field := range cachedMeta(a.Type()).fields {
+		if !field.exported {
+			continue
+		}
+		if d.first && len(d.paths) > 0 {
+			return
+		}
+		d.diffValue(a.FieldByIndex(field.index), b.FieldByIndex(field.index), prefix+field.name)
+	}
+}
+
+func (d *differ) diffValue(a, b reflect.Value, path string) {
+	if a.Type() == timeType {
+		if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+			d.paths = append(d.paths, path)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Interface:
+		// Interfaces holding values of the same type compare as the values.
+		if !a.IsNil() && !b.IsNil() && a.Elem().Type() == b.Elem().Type() {
+			d.diffValue(a.Elem(), b.Elem(), path)
+			return
+		}
+	case reflect.Struct:
+		if isNestedStruct(a.Type()) {
+			d.diffStruct(a, b, path+".")
+			return
+		}
+	case reflect.Ptr:
+		if !a.IsNil() && !b.IsNil() && a.Elem().Kind() == reflect.Struct {
+			key := [2]uintptr{a.Pointer(), b.Pointer()}
+			if d.visited[key] {
+				return
+			}
+			d.visited[key] = true
+			defer delete(d.visited, key)
+			d.diffValue(a.Elem(), b.Elem(), path)
+			return
+		}
+	}
+
+	// Treat values that are both empty (e.g. nil and empty slices) as equal.
+	if isZero(a) && isZero(b) {
+		return
+	}
+
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		d.paths = append(d.paths, path)
+	}
+}
@@ -0,0 +1,250 @@
+package structmerge
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Geo struct {
+	Lat float64
+	Lng float64
+}
+
+type Location struct {
+	Address Address
+	Geo     Geo
+}
+
+type Event struct {
+	Name     string
+	Location Location
+	Host     *Person
+	Tags     []string
+	Start    time.Time
+	secret   string
+	Internal string `merge:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	a := Event{
+		Name: "Meetup",
+		Location: Location{
+			Address: Address{Street: "1 Main St", City: "Kampala"},
+			Geo:     Geo{Lat: 0.31, Lng: 32.58},
+		},
+		Host:     &Person{Name: "Alice", Address: &Address{City: "Kampala"}},
+		Start:    start,
+		secret:   "a",
+		Internal: "a",
+	}
+
+	b := a
+	b.Location.Address.City = "Entebbe"
+	b.Location.Geo.Lng = 32.44
+	b.Host = &Person{Name: "Alice", Address: &Address{City: "Jinja"}}
+	b.Tags = []string{}                                // empty vs nil is not a change
+	b.Start = start.In(time.FixedZone("EAT", 3*60*60)) // same instant
+	b.secret = "b"
+	b.Internal = "b"
+
+	paths, err := Diff(a, &b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Location.Address.City",
+		"Location.Geo.Lng",
+		"Host.Address.City",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Diff() = %v, want %v", paths, expected)
+	}
+}
+
+func TestDiffPointersAndTime(t *testing.T) {
+	a := Event{Start: time.Now()}
+	b := Event{Start: a.Start.Add(time.Second), Host: &Person{Name: "Bob"}}
+
+	paths, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"Host", "Start"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Diff() = %v, want %v", paths, expected)
+	}
+}
+
+type Reading struct {
+	Value interface{}
+}
+
+func TestDiffInterfaceTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		a, b     Reading
+		expected []string
+	}{
+		{Reading{now}, Reading{"noon"}, []string{"Value"}},
+		{Reading{now}, Reading{}, []string{"Value"}},
+		{Reading{}, Reading{now}, []string{"Value"}},
+		{Reading{now}, Reading{now.Add(time.Second)}, []string{"Value"}},
+		{Reading{now}, Reading{now.In(time.FixedZone("EAT", 3*60*60))}, nil},
+	}
+
+	for _, tt := range tests {
+		paths, err := Diff(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(paths, tt.expected) {
+			t.Errorf("Diff(%v, %v) = %v, want %v", tt.a, tt.b, paths, tt.expected)
+		}
+	}
+}
+
+func TestDiffSQLNullTypes(t *testing.T) {
+	a := NullableRow{Name: sql.NullString{String: "x", Valid: true}, Count: sql.NullInt64{Int64: 1}}
+	b := NullableRow{Name: sql.NullString{String: "y", Valid: true}}
+
+	paths, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Invalid values are equal whatever they hold.
+	expected := []string{"Name"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Diff() = %v, want %v", paths, expected)
+	}
+}
+
+func TestDiffFeedsInclude(t *testing.T) {
+	dst := TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Old City"}}
+	src := TestStruct{Name: "Alice", Age: 31, Address: Address{City: "New City"}}
+
+	paths, err := Diff(dst, src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := Merge(&dst, src, Config{Include: paths}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Errorf("expected %#v, got %#v", src, dst)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	if _, err := Diff(TestStruct{}, Person{}); err != ErrTypeMismatch {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+	if _, err := Diff(1, 2); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	base := Event{
+		Name:     "Launch",
+		Location: Location{Address: Address{City: "Kampala"}},
+		Host:     &Person{Name: "Alice"},
+		Start:    start,
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(e *Event)
+		expected bool
+	}{
+		{"Identical", func(e *Event) {}, true},
+		{"Same instant in another zone", func(e *Event) { e.Start = start.In(time.FixedZone("EAT", 3*3600)) }, true},
+		{"Distinct but equal pointers", func(e *Event) { e.Host = &Person{Name: "Alice"} }, true},
+		{"Nil and empty slice", func(e *Event) { e.Tags = []string{} }, true},
+		{"Ignored fields", func(e *Event) { e.secret, e.Internal = "x", "y" }, true},
+		{"Nested field", func(e *Event) { e.Location.Address.City = "Gulu" }, false},
+		{"Pointer field", func(e *Event) { e.Host = &Person{Name: "Bob"} }, false},
+		{"Time", func(e *Event) { e.Start = start.Add(time.Hour) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.modify(&other)
+
+			equal, err := Equal(base, &other)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if equal != tt.expected {
+				t.Errorf("Equal() = %v, want %v", equal, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEqualCycles(t *testing.T) {
+	a := &Node{Name: "a"}
+	a.Next = a
+	b := &Node{Name: "a"}
+	b.Next = b
+
+	equal, err := Equal(a, b)
+	if err != nil || !equal {
+		t.Errorf("Equal() = %v, %v; want true", equal, err)
+	}
+
+	b.Next = &Node{Name: "b", Next: b}
+	if equal, err := Equal(a, b); err != nil || equal {
+		t.Errorf("Equal() = %v, %v; want false", equal, err)
+	}
+}
+
+type sharedGeo struct {
+	A, B *Geo
+}
+
+func TestDiffSharedPointers(t *testing.T) {
+	p := &Geo{Lat: 1}
+	q := &Geo{Lat: 2}
+	from, to := sharedGeo{A: p, B: p}, sharedGeo{A: q, B: q}
+
+	// A pointer shared by two fields is compared under both.
+	paths, err := Diff(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"A.Lat", "B.Lat"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Diff() = %v, want %v", paths, expected)
+	}
+
+	patch, err := CreatePatch(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dst := sharedGeo{A: &Geo{Lat: 1}, B: &Geo{Lat: 1}}
+	if err := ApplyPatch(&dst, patch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, to) {
+		t.Errorf("ApplyPatch() = %+v %+v, want %+v %+v", dst.A, dst.B, to.A, to.B)
+	}
+}
+
+func TestEqualErrors(t *testing.T) {
+	if equal, err := Equal(Event{}, Person{}); equal || err != ErrTypeMismatch {
+		t.Errorf("expected false and ErrTypeMismatch, got %v, %v", equal, err)
+	}
+	if equal, err := Equal(42, Event{}); equal || err != ErrInvalidSource {
+		t.Errorf("expected false and ErrInvalidSource, got %v, %v", equal, err)
+	}
+}
@@ -0,0 +1,47 @@
+package structmerge
+
+import (
+	"context"
+	"reflect"
+)
+
+// DryRunResult describes the changes a merge would make.
+type DryRunResult struct {
+	// Changed lists the paths of the fields that would be set to a new
+	// value, in merge order.
+	Changed []string
+}
+
+// MergeDryRun validates a merge of src into dst and reports which fields it
+// would change, without modifying dst. It fails if an Include path does not
+// exist on the struct type or if a Merger implementation returns an error.
+// cfg.OnFieldSet, if set, is still called for every field that would change.
+func MergeDryRun(dst, src interface{}, cfg Config) (*DryRunResult, error) {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidDestination
+	}
+
+	config := resolveConfig(dstValue.Elem().Type(), cfg)
+	for _, path := range config.Include {
+		if err := checkPath(dstValue.Elem().Type(), path); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &DryRunResult{}
+	onFieldSet := cfg.OnFieldSet
+	config.OnFieldSet = func(path string, oldVal, newVal reflect.Value) {
+		result.Changed = append(result.Changed, path)
+		if onFieldSet != nil {
+			onFieldSet(path, oldVal, newVal)
+		}
+	}
+	config.DryRun = true
+
+	err := mergeValues(newMergeState(context.Background()), dstValue, reflect.ValueOf(src), config, "")
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeDryRun(t *testing.T) {
+	dst := MapStruct{
+		Labels:    map[string]string{"env": "prod"},
+		Addresses: map[string]Address{"home": {City: "Kampala"}},
+	}
+	src := MapStruct{
+		Labels:    map[string]string{"team": "core"},
+		Counts:    map[string]int{"a": 1},
+		Addresses: map[string]Address{"home": {City: "Kampala"}},
+	}
+
+	before := MapStruct{
+		Labels:    map[string]string{"env": "prod"},
+		Addresses: map[string]Address{"home": {City: "Kampala"}},
+	}
+
+	var calls []string
+	cfg := Config{
+		MapMergeStrategy: MapMergeKeys,
+		OnFieldSet: func(path string, oldVal, newVal reflect.Value) {
+			calls = append(calls, path)
+		},
+	}
+
+	result, err := MergeDryRun(&dst, src, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"Labels", "Counts"}
+	if !reflect.DeepEqual(result.Changed, expected) {
+		t.Errorf("Changed = %v, want %v", result.Changed, expected)
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("OnFieldSet calls = %v, want %v", calls, expected)
+	}
+	if !reflect.DeepEqual(dst, before) {
+		t.Errorf("dry run modified dst: %#v", dst)
+	}
+}
+
+func TestMergeDryRunNestedAndAppend(t *testing.T) {
+	dst := AppendStruct{Tags: make([]string, 1, 4), Notes: []string{"a"}}
+	src := AppendStruct{Tags: []string{"x"}, Notes: []string{"a"}}
+
+	result, err := MergeDryRun(&dst, src, Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Changed, []string{"Tags"}) {
+		t.Errorf("Changed = %v, want [Tags]", result.Changed)
+	}
+	if len(dst.Tags) != 1 {
+		t.Errorf("dry run modified dst: %#v", dst)
+	}
+
+	person := TestStruct{Name: "Alice", Address: Address{City: "Kampala"}}
+	result, err = MergeDryRun(&person, TestStruct{Name: "Alice", Age: 3, Address: Address{City: "Gulu"}}, Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Changed, []string{"Age", "Address.City"}) {
+		t.Errorf("Changed = %v", result.Changed)
+	}
+	if person.Age != 0 || person.Address.City != "Kampala" {
+		t.Errorf("dry run modified dst: %#v", person)
+	}
+}
+
+func TestMergeDryRunMerger(t *testing.T) {
+	var dst, src Document
+	dst.Meta.Version = 2
+	src.Meta.Version = 5
+
+	cfg := Config{Exclude: []string{"Meta.Checksum"}}
+	result, err := MergeDryRun(&dst, src, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Changed, []string{"Meta.Version"}) {
+		t.Errorf("Changed = %v", result.Changed)
+	}
+	if dst.Meta.Version != 2 {
+		t.Errorf("dry run modified dst: %v", dst.Meta.Version)
+	}
+
+	src.Meta.Version = 1
+	if _, err := MergeDryRun(&dst, src, cfg); !errors.Is(err, errBadVersion) {
+		t.Errorf("expected the Merger error, got %v", err)
+	}
+}
+
+func TestMergeDryRunInvalidInclude(t *testing.T) {
+	var dst TestStruct
+	_, err := MergeDryRun(&dst, TestStruct{}, Config{Include: []string{"Name", "Address.Stret"}})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expected ErrInvalidPath, got %v", err)
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Address.Stret" {
+		t.Errorf("expected path Address.Stret, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package structmerge
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MergeFromEnv sets the fields of dst, which must be a pointer to a struct,
+// from environment variables. The variable for a field is named after prefix
+// and the field path joined with underscores, in upper case: with prefix
+// "APP", Address.Street is read from APP_ADDRESS_STREET. Unset and empty
+// variables leave their fields untouched.
+//
+// Values are parsed into string, bool, integer, float and time.Duration
+// fields, pointers to them, and types implementing encoding.TextUnmarshaler.
+// Nested structs and pointers to structs are filled recursively; a nil
+// pointer is only allocated if one of the fields it leads to is set. Fields
+// tagged `merge:"-"` and masked fields are skipped.
+//
+// Values that cannot be parsed into their field, and non-empty variables
+// starting with prefix and an underscore that match no field, including the
+// skipped ones, are reported together in a MultiError of *FieldError values;
+// the remaining fields are still set.
+func MergeFromEnv(dst interface{}, prefix string) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	e := envMerger{
+		known:    make(map[string]bool),
+		visiting: map[reflect.Type]bool{dstValue.Elem().Type(): true},
+	}
+	e.mergeStruct(dstValue.Elem(), strings.ToUpper(prefix), "")
+
+	if prefix != "" {
+		var unknown []string
+		for _, kv := range os.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			if value != "" && strings.HasPrefix(name, strings.ToUpper(prefix)+"_") && !e.known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			e.errs = append(e.errs, &FieldError{Path: name, Err: ErrUnknownKey})
+		}
+	}
+
+	if len(e.errs) > 0 {
+		return e.errs
+	}
+	return nil
+}
+
+// envMerger holds the state of a MergeFromEnv call.
+type envMerger struct {
+	known    map[string]bool       // names of the variables matching a field
+	visiting map[reflect.Type]bool // struct types being filled, to stop recursion
+	errs     MultiError
+}
+
+// mergeStruct fills the struct v from the variables named after name and
+// reports whether any field was set.
+func (e *envMerger) mergeStruct(v reflect.Value, name, prefix string) bool {
+	set := false
+	for _, field := range cachedMeta(v.Type()).fields {
+		if !field.exported || field.masked {
+			continue
+		}
+
+		fieldName := strings.ToUpper(field.name)
+		if name != "" {
+			fieldName = name + "_" + fieldName
+		}
+		if e.mergeField(v.FieldByIndex(field.index), fieldName, prefix+field.name) {
+			set = true
+		}
+	}
+	return set
+}
+
+func (e *envMerger) mergeField(v reflect.Value, name, path string) bool {
+	t := v.Type()
+	switch {
+	case isNestedStruct(t):
+		return e.mergeStruct(v, name, path+".")
+
+	case isStructPointer(t) && !e.visiting[t.Elem()]:
+		e.visiting[t.Elem()] = true
+		defer delete(e.visiting, t.Elem())
+
+		if !v.IsNil() {
+			return e.mergeStruct(v.Elem(), name, path+".")
+		}
+		elem := reflect.New(t.Elem())
+		if !e.mergeStruct(elem.Elem(), name, path+".") {
+			return false
+		}
+		v.Set(elem)
+		return true
+	}
+
+	e.known[name] = true
+	text := os.Getenv(name)
+	if text == "" {
+		return false
+	}
+
+	if err := parseText(v, text); err != nil {
+		e.errs = append(e.errs, &FieldError{Path: path, Err: err})
+		return false
+	}
+	return true
+}
+
+// parseText sets the settable v to the value parsed from text.
+func parseText(v reflect.Value, text string) error {
+	t := v.Type()
+	if isTextUnmarshaler(t) {
+		parsed, err := unmarshalText(t, text)
+		if err != nil {
+			return err
+		}
+		v.Set(parsed)
+		return nil
+	}
+
+	if t == durationType {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		if err := parseText(elem.Elem(), text); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return ErrTypeMismatch
+	}
+	return nil
+}
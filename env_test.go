@@ -0,0 +1,97 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type EnvConfig struct {
+	Host     string
+	Port     int
+	Debug    bool
+	Ratio    float64
+	Timeout  time.Duration
+	MaxConns *uint16
+	Started  time.Time
+	Address  Address
+	Backup   *Address
+	Mirror   *Address
+	Secret   string `merge:"-"`
+	Password string `merge:"mask"`
+}
+
+func TestMergeFromEnv(t *testing.T) {
+	t.Setenv("APP_HOST", "example.com")
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_RATIO", "0.5")
+	t.Setenv("APP_TIMEOUT", "1m30s")
+	t.Setenv("APP_MAXCONNS", "100")
+	t.Setenv("APP_STARTED", "2024-01-02T03:04:05Z")
+	t.Setenv("APP_ADDRESS_CITY", "Kampala")
+	t.Setenv("APP_BACKUP_STREET", "1 Backup Rd")
+	t.Setenv("APP_MIRROR_CITY", "")
+
+	dst := EnvConfig{Host: "localhost", Port: 80, Address: Address{Country: "Uganda"}}
+	if err := MergeFromEnv(&dst, "app"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	maxConns := uint16(100)
+	expected := EnvConfig{
+		Host:     "example.com",
+		Port:     8080,
+		Debug:    true,
+		Ratio:    0.5,
+		Timeout:  90 * time.Second,
+		MaxConns: &maxConns,
+		Started:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Address:  Address{City: "Kampala", Country: "Uganda"},
+		Backup:   &Address{Street: "1 Backup Rd"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("MergeFromEnv() = %+v, want %+v", dst, expected)
+	}
+}
+
+func TestMergeFromEnvErrors(t *testing.T) {
+	t.Setenv("SVC_HOST", "example.com")
+	t.Setenv("SVC_PORT", "eighty")
+	t.Setenv("SVC_TIMEOUT", "soon")
+	t.Setenv("SVC_SECRET", "x")
+	t.Setenv("SVC_PASSWORD", "x")
+	t.Setenv("SVC_UNKNOWN", "x")
+
+	var dst EnvConfig
+	err := MergeFromEnv(&dst, "SVC")
+
+	var errs MultiError
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected a MultiError, got %v", err)
+	}
+
+	var paths []string
+	for _, e := range errs {
+		var fieldErr *FieldError
+		if !errors.As(e, &fieldErr) {
+			t.Fatalf("expected a *FieldError, got %#v", e)
+		}
+		paths = append(paths, fieldErr.Path)
+	}
+	expected := []string{"Port", "Timeout", "SVC_PASSWORD", "SVC_SECRET", "SVC_UNKNOWN"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("error paths = %v, want %v", paths, expected)
+	}
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Error("expected errors.Is to match ErrUnknownKey")
+	}
+	if dst.Host != "example.com" {
+		t.Errorf("expected the valid fields to be set, got %+v", dst)
+	}
+
+	if err := MergeFromEnv(dst, "SVC"); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// ParseFieldMask splits a comma-separated field mask, as carried by a
+// google.protobuf.FieldMask in its JSON form, into a list of paths suitable
+// for Config.Include. Whitespace around paths is removed and empty entries
+// are dropped.
+func ParseFieldMask(mask string) []string {
+	var paths []string
+	for _, path := range strings.Split(mask, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// MergeWithFieldMask merges the fields of src listed in mask into dst,
+// following the update semantics of a gRPC FieldMask: masked fields are
+// copied even when they are empty in src, so that they can be cleared.
+// Paths may use Go field names or `json` tag names, e.g. "name,address.street".
+// An empty mask merges every field.
+//
+// Paths that do not name a field make it fail with an ErrorList of
+// *FieldError values wrapping ErrInvalidPath, leaving dst untouched.
+func MergeWithFieldMask(dst, src interface{}, mask string) error {
+	cfg := Config{
+		Option:      IncludeAll,
+		Include:     ParseFieldMask(mask),
+		UseJSONTags: true,
+	}
+	if err := ValidateConfig(cfg, src); err != nil {
+		return err
+	}
+	return Merge(dst, src, cfg)
+}
+
+// MergeJSON decodes the JSON object jsonSrc into a new value of the struct
+// type dst points to and merges its non-empty fields into dst, restricted to
+// the paths in fieldMask if any are given. Paths may use Go field names or
+// `json` tag names. Unlike MergeWithFieldMask, empty values never clear a
+// field; use MergePatch to clear fields with null.
+//
+// Paths that do not name a field make it fail with an ErrorList of
+// *FieldError values wrapping ErrInvalidPath, leaving dst untouched.
+func MergeJSON(dst interface{}, jsonSrc []byte, fieldMask ...string) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	src := reflect.New(dstValue.Elem().Type())
+	if err := json.Unmarshal(jsonSrc, src.Interface()); err != nil {
+		return err
+	}
+
+	cfg := Config{
+		Option:      ExcludeEmpty,
+		Include:     fieldMask,
+		UseJSONTags: true,
+	}
+	if err := ValidateConfig(cfg, src.Type()); err != nil {
+		return err
+	}
+	return Merge(dst, src.Elem().Interface(), cfg)
+}
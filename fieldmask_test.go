@@ -0,0 +1,142 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Book struct {
+	Name    string      `json:"name"`
+	Author  string      `json:"author"`
+	Pages   int         `json:"pages"`
+	Address JSONAddress `json:"address"`
+}
+
+// UpdateBookRequest mirrors a gRPC update request carrying a FieldMask.
+type UpdateBookRequest struct {
+	Book       Book
+	UpdateMask string
+}
+
+func TestParseFieldMask(t *testing.T) {
+	tests := []struct {
+		mask     string
+		expected []string
+	}{
+		{"", nil},
+		{"name", []string{"name"}},
+		{"name,address.street", []string{"name", "address.street"}},
+		{" name , pages ,, ", []string{"name", "pages"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseFieldMask(tt.mask); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseFieldMask(%q) = %q, want %q", tt.mask, got, tt.expected)
+		}
+	}
+}
+
+func TestMergeWithFieldMask(t *testing.T) {
+	stored := Book{Name: "Old", Author: "Alice", Pages: 100, Address: JSONAddress{Street: "Old St", PostalCode: "111"}}
+
+	tests := []struct {
+		name     string
+		req      UpdateBookRequest
+		expected Book
+	}{
+		{
+			name: "Masked fields only",
+			req: UpdateBookRequest{
+				Book:       Book{Name: "New", Author: "Bob", Address: JSONAddress{Street: "New St"}},
+				UpdateMask: "name, address.street",
+			},
+			expected: Book{Name: "New", Author: "Alice", Pages: 100, Address: JSONAddress{Street: "New St", PostalCode: "111"}},
+		},
+		{
+			name: "Masked empty field is cleared",
+			req: UpdateBookRequest{
+				Book:       Book{Name: "New"},
+				UpdateMask: "pages",
+			},
+			expected: Book{Name: "Old", Author: "Alice", Address: JSONAddress{Street: "Old St", PostalCode: "111"}},
+		},
+		{
+			name: "Go field names",
+			req: UpdateBookRequest{
+				Book:       Book{Author: "Bob"},
+				UpdateMask: "Author",
+			},
+			expected: Book{Name: "Old", Author: "Bob", Pages: 100, Address: JSONAddress{Street: "Old St", PostalCode: "111"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stored
+			if err := MergeWithFieldMask(&got, tt.req.Book, tt.req.UpdateMask); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("MergeWithFieldMask() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeWithFieldMaskInvalidPath(t *testing.T) {
+	got := Book{Name: "Old"}
+	err := MergeWithFieldMask(&got, Book{Name: "New"}, "name,titel")
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expected ErrInvalidPath, got %v", err)
+	}
+	if got.Name != "Old" {
+		t.Errorf("destination modified: %+v", got)
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	newStored := func() Book {
+		return Book{Name: "Old", Author: "Alice", Pages: 100, Address: JSONAddress{Street: "Old St", PostalCode: "111"}}
+	}
+	body := []byte(`{"name": "New", "author": "", "pages": 250, "address": {"street": "New St"}}`)
+
+	tests := []struct {
+		name     string
+		mask     []string
+		expected Book
+	}{
+		{
+			name:     "No mask",
+			expected: Book{Name: "New", Author: "Alice", Pages: 250, Address: JSONAddress{Street: "New St", PostalCode: "111"}},
+		},
+		{
+			name:     "Masked fields only",
+			mask:     []string{"name", "Address.Street", "author"},
+			expected: Book{Name: "New", Author: "Alice", Pages: 100, Address: JSONAddress{Street: "New St", PostalCode: "111"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := newStored()
+			if err := MergeJSON(&stored, body, tt.mask...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stored != tt.expected {
+				t.Errorf("got %+v, want %+v", stored, tt.expected)
+			}
+		})
+	}
+
+	stored := newStored()
+	if err := MergeJSON(&stored, body, "title"); !errors.Is(err, ErrInvalidPath) || stored != newStored() {
+		t.Errorf("expected ErrInvalidPath and no change, got %v, %+v", err, stored)
+	}
+	if err := MergeJSON(&stored, []byte(`{"pages": "many"}`)); err == nil {
+		t.Error("expected a decoding error")
+	}
+	if err := MergeJSON(stored, body); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
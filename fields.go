@@ -0,0 +1,234 @@
+package structmerge
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	mergerType          = reflect.TypeOf((*Merger)(nil)).Elem()
+	ctxMergerType       = reflect.TypeOf((*MergerWithContext)(nil)).Elem()
+	maskerType          = reflect.TypeOf((*Masker)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	valuerType          = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	rawMessageType      = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// structMeta holds the pre-computed reflection data of a struct type.
+type structMeta struct {
+	isTime bool        // the type is time.Time
+	merger bool        // a pointer to the type implements a merger interface
+	fields []fieldMeta // the fields that take part in a merge
+}
+
+// fieldMeta holds the pre-computed reflection data of a struct field.
+type fieldMeta struct {
+	index    []int // index sequence for reflect.Value.FieldByIndex
+	name     string
+	json     string // name given by the `json` tag, if any
+	opts     tagOptions
+	exported bool
+	merger   bool     // the field is exported and a pointer to it implements a merger interface
+	nested   bool     // the field is a struct that is merged field by field
+	ptr      bool     // the field is a pointer to such a struct
+	masked   bool     // the field is tagged `merge:"mask"` or implements Masker
+	groups   []string // the groups named by `merge:"group=..."`
+}
+
+// fieldByKey returns the field named key, matching the Go name first and,
+// if useJSON is set, the `json` tag name.
+func (m *structMeta) fieldByKey(key string, useJSON bool) (fieldMeta, bool) {
+	for _, f := range m.fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+
+	if useJSON {
+		for _, f := range m.fields {
+			if f.json == key {
+				return f, true
+			}
+		}
+	}
+	return fieldMeta{}, false
+}
+
+// metaCache maps a reflect.Type to its *structMeta.
+var metaCache sync.Map
+
+// cachedMeta returns the metadata of the struct type t, computing and caching
+// it on first use. It is safe for concurrent use.
+func cachedMeta(t reflect.Type) *structMeta {
+	if m, ok := metaCache.Load(t); ok {
+		return m.(*structMeta)
+	}
+	m, _ := metaCache.LoadOrStore(t, newStructMeta(t))
+	return m.(*structMeta)
+}
+
+func newStructMeta(t reflect.Type) *structMeta {
+	m := &structMeta{
+		isTime: t == timeType,
+		merger: isMerger(t),
+	}
+	m.fields = typeFields(t, nil, nil)
+	return m
+}
+
+// typeFields returns the fields of the struct type t that take part in a
+// merge. The fields of embedded structs are promoted into the list as if they
+// were declared on t, following Go's own rules: a field declared on t hides
+// a promoted field of the same name. Embedded pointers, time.Time and types
+// implementing Merger are kept as regular fields named after their type.
+func typeFields(t reflect.Type, index []int, hidden map[string]bool) []fieldMeta {
+	// Names declared at this level hide promoted fields of the same name.
+	names := make(map[string]bool, len(hidden)+t.NumField())
+	for name := range hidden {
+		names[name] = true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); !isFlattened(field) {
+			names[field.Name] = true
+		}
+	}
+
+	var fields []fieldMeta
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Fields tagged with `merge:"-"` are never merged.
+		tag := field.Tag.Get("merge")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if isFlattened(field) {
+			for _, f := range typeFields(field.Type, fieldIndex, names) {
+				names[f.name] = true
+				fields = append(fields, f)
+			}
+			continue
+		}
+
+		if hidden[field.Name] {
+			continue
+		}
+
+		fields = append(fields, fieldMeta{
+			index:    fieldIndex,
+			name:     field.Name,
+			json:     jsonName(field),
+			opts:     tagOptions(tag),
+			exported: field.IsExported(),
+			merger:   field.IsExported() && isMerger(field.Type),
+			nested:   isNestedStruct(field.Type),
+			ptr:      isStructPointer(field.Type),
+			masked:   tagOptions(tag).Contains("mask") || isMasked(field.Type),
+			groups:   tagGroups(tagOptions(tag)),
+		})
+	}
+	return fields
+}
+
+// tagFlags lists the merge tag options given without a value.
+var tagFlags = map[string]bool{
+	"-": true, "mask": true, "omitempty": true, "append": true,
+	"replace": true, "immutable": true, "key": true, "coerce": true,
+}
+
+// tagGroups returns the groups named by the `merge:"group=..."` option. In
+// `merge:"group=pii,audit"` the names following the option, up to the next
+// known option, are groups as well.
+func tagGroups(opts tagOptions) []string {
+	var groups []string
+	inGroups := false
+	for _, name := range strings.Split(string(opts), ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.HasPrefix(name, "group=") || strings.HasPrefix(name, "group:"):
+			inGroups = true
+			name = name[len("group="):]
+		case !inGroups || tagFlags[name] || strings.ContainsAny(name, ":="):
+			inGroups = false
+			continue
+		}
+		if name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups
+}
+
+// groupPaths returns the paths of the fields of the struct type t, nested
+// structs and structs pointed to included, that belong to one of groups.
+// visiting holds the struct types being walked, so that pointers leading
+// back to them are not followed.
+func groupPaths(t reflect.Type, groups []string, prefix string, visiting map[reflect.Type]bool) []string {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var paths []string
+	for _, field := range cachedMeta(t).fields {
+		switch {
+		case inGroups(field.groups, groups):
+			paths = append(paths, prefix+field.name)
+		case field.nested:
+			paths = append(paths, groupPaths(t.FieldByIndex(field.index).Type, groups, prefix+field.name+".", visiting)...)
+		case field.ptr:
+			if elem := t.FieldByIndex(field.index).Type.Elem(); !visiting[elem] {
+				paths = append(paths, groupPaths(elem, groups, prefix+field.name+".", visiting)...)
+			}
+		}
+	}
+	return paths
+}
+
+// inGroups reports whether one of names is in groups.
+func inGroups(names, groups []string) bool {
+	for _, name := range names {
+		for _, group := range groups {
+			if name == group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isMasked reports whether t, or a pointer to t, implements Masker.
+func isMasked(t reflect.Type) bool {
+	return t.Implements(maskerType) || reflect.PointerTo(t).Implements(maskerType)
+}
+
+// isFlattened reports whether the fields of the embedded field are promoted
+// into the parent's field list.
+func isFlattened(field reflect.StructField) bool {
+	return field.Anonymous && isNestedStruct(field.Type)
+}
+
+// isNestedStruct reports whether t is a struct that is merged field by field,
+// that is neither time.Time, a Merger or MergerWithContext nor a nullable
+// database type.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		t != timeType &&
+		!isMerger(t) &&
+		!isNullType(t)
+}
+
+// isMerger reports whether a pointer to t implements Merger or
+// MergerWithContext.
+func isMerger(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(mergerType) || p.Implements(ctxMergerType)
+}
@@ -0,0 +1,109 @@
+package structmerge
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCachedMeta(t *testing.T) {
+	typ := reflect.TypeOf(TaggedStruct{})
+
+	var wg sync.WaitGroup
+	metas := make([]*structMeta, 8)
+	for i := range metas {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			metas[i] = cachedMeta(typ)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, m := range metas[1:] {
+		if m != metas[0] {
+			t.Fatal("expected every caller to get the same cached metadata")
+		}
+	}
+
+	var names []string
+	for _, f := range metas[0].fields {
+		names = append(names, f.name)
+	}
+	expected := []string{"Name", "Address"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("fields = %v, want %v", names, expected)
+	}
+
+	if !cachedMeta(reflect.TypeOf(Plan{})).fields[0].merger {
+		t.Error("expected Plan.Date to be detected as a Merger")
+	}
+}
+
+type benchInner struct {
+	A, B, C, D, E string
+	F, G, H, I, J int
+}
+
+type benchStruct struct {
+	S1, S2, S3, S4, S5 string
+	I1, I2, I3, I4, I5 int
+	B1, B2, B3, B4, B5 bool
+	Inner1, Inner2     benchInner
+	Inner3, Inner4     benchInner
+	Inner5             benchInner
+}
+
+func newBenchStruct() benchStruct {
+	inner := benchInner{A: "a", B: "b", C: "c", D: "d", E: "e", F: 1, G: 2, H: 3, I: 4, J: 5}
+	return benchStruct{
+		S1: "1", S2: "2", S3: "3", S4: "4", S5: "5",
+		I1: 1, I2: 2, I3: 3, I4: 4, I5: 5,
+		B1: true, B3: true, B5: true,
+		Inner1: inner, Inner2: inner, Inner3: inner, Inner4: inner, Inner5: inner,
+	}
+}
+
+func BenchmarkMergeCached(b *testing.B) {
+	src := newBenchStruct()
+	var dst benchStruct
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Merge(&dst, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMergeUncached(b *testing.B) {
+	src := newBenchStruct()
+	var dst benchStruct
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		metaCache.Range(func(key, _ interface{}) bool {
+			metaCache.Delete(key)
+			return true
+		})
+		if err := Merge(&dst, src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMergeFiltered(b *testing.B) {
+	src := newBenchStruct()
+	var dst benchStruct
+	cfg := Config{
+		Include: []string{"S1", "I1", "Inner1", "Inner2", "Inner3", "Inner4", "Inner5"},
+		Exclude: []string{"Inner1.A", "Inner2.*", "Inner5.J"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Merge(&dst, src, cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package structmerge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// MergeFromMap merges the values of src into dst, which must be a pointer to
+// a struct. Map keys are matched to field names and, when cfg.UseJSONTags is
+// set, to `json` tag names. Nested maps are merged into nested structs (or
+// pointers to structs) recursively.
+//
+// Values are converted to the field types where possible, including
+// json.Number values from a decoder using UseNumber. The merge option, tags,
+// Include/Exclude paths and Transformers apply as they do for Merge, with the
+// missing fields treated as absent rather than empty. Nil values are skipped.
+//
+// Keys that do not match any field are ignored unless cfg.StrictKeys is set,
+// in which case a *FieldError wrapping ErrUnknownKey is returned.
+func MergeFromMap(dst interface{}, src map[string]interface{}, cfg ...Config) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	config := resolveConfig(dstValue.Elem().Type(), configOf(cfg))
+	state := newMergeState(context.Background())
+	state.filter = newPathFilter(config)
+	return mergeFromMap(state, dstValue.Elem(), src, config, "")
+}
+
+// MergePatch applies patch to dst, which must be a pointer to a struct, as a
+// JSON Merge Patch (RFC 7396) decoded into a map: nil values reset fields to
+// their zero value, whatever the merge option, and other values are merged
+// as by MergeFromMap. Nested maps patch nested structs, so a REST PATCH
+// endpoint can decode its request body and pass it on as is.
+func MergePatch(dst interface{}, patch map[string]interface{}, cfg ...Config) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	config := resolveConfig(dstValue.Elem().Type(), configOf(cfg))
+	state := newMergeState(context.Background())
+	state.nullAsZero = true
+	state.filter = newPathFilter(config)
+	return mergeFromMap(state, dstValue.Elem(), patch, config, "")
+}
+
+// MergeFromJSON merges the JSON object in data into dst, which must be a
+// pointer to a struct. It decodes data into a map, keeping numbers as
+// json.Number to avoid precision loss, and merges it with MergeFromMap,
+// so only the keys present in data are merged and null values are skipped.
+func MergeFromJSON(dst interface{}, data []byte, cfg ...Config) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var src map[string]interface{}
+	if err := dec.Decode(&src); err != nil {
+		return err
+	}
+	return MergeFromMap(dst, src, cfg...)
+}
+
+func mergeFromMap(state *mergeState, dst reflect.Value, src map[string]interface{}, cfg Config, prefix string) error {
+	meta := cachedMeta(dst.Type())
+
+	for key, value := range src {
+		if err := mergeMapEntry(state, dst, meta, key, value, cfg, prefix); err != nil {
+			if err := state.fieldError(cfg, prefix+key, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if prefix == "" {
+		return state.collected()
+	}
+	return nil
+}
+
+// mergeMapEntry merges the map entry key: value into the matching field of
+// the struct dst described by meta.
+func mergeMapEntry(state *mergeState, dst reflect.Value, meta *structMeta, key string, value interface{}, cfg Config, prefix string) error {
+	field, ok := meta.fieldByKey(key, cfg.UseJSONTags)
+	if !ok {
+		if cfg.StrictKeys {
+			return &FieldError{Path: prefix + key, Err: ErrUnknownKey}
+		}
+		return nil
+	}
+
+	path := prefix + field.name
+	_, isMap := value.(map[string]interface{})
+	if field.masked {
+		state.logMasked(cfg, path)
+		return nil
+	}
+	if (value == nil && !state.nullAsZero) || state.filter.skip(path, isMap && !field.merger) {
+		return nil
+	}
+
+	dstField := dst.FieldByIndex(field.index)
+	if !dstField.CanSet() {
+		return nil
+	}
+
+	if value == nil {
+		// MergePatch deletes fields given null.
+		patchCfg := cfg
+		patchCfg.Option = IncludeAll
+		return mergeField(state, dstField, reflect.Zero(dstField.Type()), field.opts, patchCfg, path)
+	}
+
+	// Nested maps are merged into nested structs.
+	if nested, ok := value.(map[string]interface{}); ok && !field.merger {
+		target := dstField
+		if target.Kind() == reflect.Ptr && target.Type().Elem().Kind() == reflect.Struct {
+			if target.IsNil() {
+				if cfg.DryRun {
+					target = reflect.New(target.Type().Elem())
+				} else {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+			}
+			target = target.Elem()
+		}
+
+		if target.Kind() == reflect.Struct && target.Type() != timeType {
+			tooDeep, err := belowMaxDepth(cfg, path)
+			if err != nil {
+				return err
+			}
+
+			// Structs below MaxDepth are decoded and merged as a whole.
+			if !tooDeep {
+				return mergeFromMap(state, target, nested, cfg, path+".")
+			}
+		}
+	}
+
+	srcField := reflect.New(dstField.Type()).Elem()
+	if err := setValue(srcField, value); err != nil {
+		return &FieldError{Path: path, Err: err}
+	}
+
+	if cfg.FieldPredicate != nil && !cfg.FieldPredicate(path, dstField, srcField) {
+		return nil
+	}
+
+	if fn, ok := mergeFunc(dstField.Type()); ok {
+		state.countField()
+		if err := mergeWith(state, dstField, srcField, cfg, path, fn); err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	if field.merger {
+		state.countField()
+		if err := mergeMerger(state, dstField, srcField, cfg, path); err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	return mergeField(state, dstField, srcField, field.opts, cfg, path)
+}
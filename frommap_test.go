@@ -0,0 +1,324 @@
+package structmerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func decodeMap(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.UseNumber()
+
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	return m
+}
+
+func TestMergeFromMap(t *testing.T) {
+	dst := TestStruct{Name: "Alice", Age: 30, Address: Address{Street: "Old St", City: "Kampala"}, Count: 2}
+
+	src := decodeMap(t, `{"Name": "Bob", "Age": 31, "Address": {"City": "Entebbe"}, "Unknown": true}`)
+	if err := MergeFromMap(&dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Keys missing from the map are left alone, even with IncludeAll.
+	expected := TestStruct{Name: "Bob", Age: 31, Address: Address{Street: "Old St", City: "Entebbe"}, Count: 2}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("MergeFromMap() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestMergeFromMapOptions(t *testing.T) {
+	src := map[string]interface{}{
+		"Name":    "",
+		"Age":     json.Number("40"),
+		"Count":   float64(7),
+		"Active":  nil,
+		"Address": map[string]interface{}{"Street": "New St", "City": "Jinja"},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected TestStruct
+	}{
+		{
+			name:     "ExcludeEmpty",
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: TestStruct{Name: "Alice", Age: 40, Active: true, Count: 7, Address: Address{Street: "New St", City: "Jinja"}},
+		},
+		{
+			name:     "Include",
+			cfg:      Config{Include: []string{"Age", "Address.City"}},
+			expected: TestStruct{Name: "Alice", Age: 40, Active: true, Address: Address{City: "Jinja"}},
+		},
+		{
+			name:     "Exclude",
+			cfg:      Config{Exclude: []string{"Name", "Address"}},
+			expected: TestStruct{Name: "Alice", Age: 40, Active: true, Count: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := TestStruct{Name: "Alice", Active: true}
+			if err := MergeFromMap(&dst, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.expected) {
+				t.Errorf("MergeFromMap() = %#v, want %#v", dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeFromMapJSONTagsAndPointers(t *testing.T) {
+	var dst JSONUser
+	src := decodeMap(t, `{"full_name": "Bob", "address": {"postal_code": "256"}}`)
+
+	if err := MergeFromMap(&dst, src, Config{UseJSONTags: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := JSONUser{FullName: "Bob", Address: JSONAddress{PostalCode: "256"}}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("MergeFromMap() = %#v, want %#v", dst, expected)
+	}
+
+	var person Person
+	src = decodeMap(t, `{"Name": "Carol", "Address": {"City": "Gulu"}}`)
+	if err := MergeFromMap(&person, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if person.Address == nil || person.Address.City != "Gulu" {
+		t.Errorf("expected nested pointer to be allocated, got %#v", person.Address)
+	}
+}
+
+func TestMergeFromMapStrictKeys(t *testing.T) {
+	var dst TestStruct
+	src := map[string]interface{}{"Address": map[string]interface{}{"Zip": "256"}}
+
+	err := MergeFromMap(&dst, src, Config{StrictKeys: true})
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Address.Zip" {
+		t.Errorf("expected path Address.Zip, got %v", err)
+	}
+
+	if err := MergeFromMap(&dst, src); err != nil {
+		t.Errorf("expected unknown keys to be ignored, got %v", err)
+	}
+}
+
+func TestMergeFromMapErrors(t *testing.T) {
+	var dst TestStruct
+	if err := MergeFromMap(dst, nil); err != ErrInvalidDestination {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+
+	err := MergeFromMap(&dst, map[string]interface{}{"Age": "not a number"})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Age" {
+		t.Errorf("expected a FieldError for Age, got %v", err)
+	}
+}
+
+type numbers struct {
+	I8 int8
+	U  uint
+	N  int
+	F  float32
+}
+
+func TestMergeFromMapNumericRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      map[string]interface{}
+		expected numbers
+		path     string
+	}{
+		{"In range", map[string]interface{}{"I8": -128.0, "U": 7, "N": 2.0, "F": 1.5}, numbers{I8: -128, U: 7, N: 2, F: 1.5}, ""},
+		{"Int overflow", map[string]interface{}{"I8": 300.0}, numbers{}, "I8"},
+		{"Int overflow from int", map[string]interface{}{"I8": 300}, numbers{}, "I8"},
+		{"Negative unsigned", map[string]interface{}{"U": -1.0}, numbers{}, "U"},
+		{"Fractional", map[string]interface{}{"N": 1.9}, numbers{}, "N"},
+		{"Float overflow", map[string]interface{}{"F": 1e300}, numbers{}, "F"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, merge := range map[string]func(*numbers) error{
+				"MergeFromMap": func(dst *numbers) error { return MergeFromMap(dst, tt.src) },
+				"FromMap":      func(dst *numbers) error { return FromMap(dst, tt.src) },
+			} {
+				var dst numbers
+				err := merge(&dst)
+				if tt.path == "" {
+					if err != nil {
+						t.Fatalf("%s: unexpected error: %v", name, err)
+					}
+				} else {
+					var fieldErr *FieldError
+					if !errors.Is(err, ErrNumericOverflow) || !errors.As(err, &fieldErr) || fieldErr.Path != tt.path {
+						t.Fatalf("%s: expected ErrNumericOverflow for %s, got %v", name, tt.path, err)
+					}
+				}
+				if dst != tt.expected {
+					t.Errorf("%s: got %+v, want %+v", name, dst, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+type Account struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Balance float64  `json:"balance"`
+	Tags    []string `json:"tags"`
+	Owner   *Person  `json:"owner"`
+}
+
+func TestMergeFromJSON(t *testing.T) {
+	dst := Account{ID: 1, Name: "Savings", Balance: 10.5, Tags: []string{"old"}}
+
+	data := []byte(`{
+		"id": 9007199254740993,
+		"name": null,
+		"balance": 99.25,
+		"tags": ["a", "b"],
+		"owner": {"Name": "Alice", "Address": {"City": "Kampala"}}
+	}`)
+
+	if err := MergeFromJSON(&dst, data, Config{UseJSONTags: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Account{
+		ID:      9007199254740993, // would lose precision as a float64
+		Name:    "Savings",        // null is skipped
+		Balance: 99.25,
+		Tags:    []string{"a", "b"},
+		Owner:   &Person{Name: "Alice", Address: &Address{City: "Kampala"}},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("MergeFromJSON() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestMergeFromJSONExcludeEmpty(t *testing.T) {
+	dst := Account{Name: "Savings", Balance: 10}
+
+	data := []byte(`{"name": "", "balance": 0, "tags": []}`)
+	cfg := Config{Option: ExcludeEmpty, UseJSONTags: true}
+	if err := MergeFromJSON(&dst, data, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Account{Name: "Savings", Balance: 10}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("MergeFromJSON() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestMergeFromJSONInvalid(t *testing.T) {
+	var dst Account
+	if err := MergeFromJSON(&dst, []byte(`[1, 2]`)); err == nil {
+		t.Error("expected an error for a non-object document")
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	newDst := func() Account {
+		return Account{
+			ID: 1, Name: "Savings", Balance: 10.5, Tags: []string{"old"},
+			Owner: &Person{Name: "Alice", Age: 30, Address: &Address{Street: "1 Main St", City: "Kampala"}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		patch    string
+		cfg      Config
+		expected func(*Account)
+	}{
+		{
+			name:     "null deletes",
+			patch:    `{"name": null, "tags": null, "owner": null}`,
+			expected: func(a *Account) { a.Name, a.Tags, a.Owner = "", nil, nil },
+		},
+		{
+			name:     "partial",
+			patch:    `{"balance": 20}`,
+			expected: func(a *Account) { a.Balance = 20 },
+		},
+		{
+			name:  "nested",
+			patch: `{"owner": {"Age": null, "Address": {"City": "Gulu", "Street": null}}}`,
+			expected: func(a *Account) {
+				a.Owner = &Person{Name: "Alice", Address: &Address{City: "Gulu"}}
+			},
+		},
+		{
+			// Null deletes regardless of the merge option.
+			name:     "exclude empty",
+			patch:    `{"name": null, "balance": 0}`,
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: func(a *Account) { a.Name = "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newDst()
+			cfg := tt.cfg
+			cfg.UseJSONTags = true
+			if err := MergePatch(&dst, decodeMap(t, tt.patch), cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := newDst()
+			tt.expected(&expected)
+			if !reflect.DeepEqual(dst, expected) {
+				t.Errorf("MergePatch() = %#v, want %#v", dst, expected)
+			}
+		})
+	}
+
+	if err := MergePatch(Account{}, nil); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
+
+func TestMergeFromMapNestedExclude(t *testing.T) {
+	cfg := Config{MapMergeStrategy: MapMergeDeep, Exclude: []string{"Addresses.home.City"}}
+	src := decodeMap(t, `{"Addresses": {"home": {"Street": "2 New St", "City": "Gulu"}}}`)
+	expected := Address{Street: "2 New St", City: "Kampala"}
+
+	for name, merge := range map[string]func(*MapStruct) error{
+		"MergeFromMap": func(dst *MapStruct) error { return MergeFromMap(dst, src, cfg) },
+		"MergePatch":   func(dst *MapStruct) error { return MergePatch(dst, src, cfg) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			dst := MapStruct{Addresses: map[string]Address{"home": {Street: "1 Home St", City: "Kampala"}}}
+			if err := merge(&dst); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := dst.Addresses["home"]; got != expected {
+				t.Errorf("got %+v, want %+v", got, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package structmerge
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+)
+
+// MergeTyped is the type-safe variant of Merge. Because dst and src share the
+// type parameter T, passing mismatched struct types is a compile-time error.
+// T must be a struct type.
+//
+// It is not named Merge because Go does not allow a generic function to
+// share its name with the existing non-generic Merge.
+func MergeTyped[T any](dst *T, src T, cfg ...Config) error {
+	return mergeValues(newMergeState(context.Background()), reflect.ValueOf(dst), reflect.ValueOf(src), configOf(cfg), "")
+}
+
+// Clone returns a deep copy of src, which must be a struct or a non-nil
+// pointer to a struct. It is like DeepCopy but keeps the type of src and
+// panics instead of returning an error, like MustMerge.
+func Clone[T any](src T) T {
+	cp, err := DeepCopy(src)
+	if err != nil {
+		panic(err)
+	}
+	return cp.(T)
+}
+
+// MergeInto returns a copy of dst with src merged into it, leaving dst
+// untouched. T must be a struct or a pointer to a struct; for a pointer the
+// result points to a new struct. dst is copied as by Clone, so the merge
+// can run on shared state without holding its lock:
+//
+//	mu.Lock()
+//	cur := state
+//	mu.Unlock()
+//	next, err := structmerge.MergeInto(cur, update)
+//	mu.Lock()
+//	state = next
+//	mu.Unlock()
+//
+// The copy shares no pointer, slice or map storage with dst, so dst keeps
+// its contents whatever the options.
+func MergeInto[T any](dst T, src T, cfg ...Config) (T, error) {
+	var zero T
+	cp, err := DeepCopy(dst)
+	if err != nil {
+		return zero, err
+	}
+	out := cp.(T)
+
+	if err := mergeCopy(newMergeState(context.Background()), &out, src, configOf(cfg)); err != nil {
+		return zero, err
+	}
+	return out, nil
+}
+
+// MergeAll returns a copy of base with each of overrides merged into it
+// from left to right with IncludeAll, leaving its arguments untouched. T
+// must be a struct or a pointer to a struct, as for MergeInto:
+//
+//	cfg, err := structmerge.MergeAll(defaults, envCfg, flagCfg)
+//
+// Since IncludeAll copies every field, later overrides should be complete
+// values; use Merge with ExcludeEmpty to layer sparse ones. On error the
+// result holds the overrides merged so far.
+func MergeAll[T any](base T, overrides ...T) (T, error) {
+	cp, err := DeepCopy(base)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	out := cp.(T)
+
+	state := newMergeState(context.Background())
+	for _, override := range overrides {
+		if err := mergeCopy(state, &out, override, Config{}); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// mergeCopy merges src into *dst, where T is a struct or a pointer to a
+// struct that *dst owns.
+func mergeCopy[T any](state *mergeState, dst *T, src T, cfg Config) error {
+	target := reflect.ValueOf(dst)
+	if target.Elem().Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	source := reflect.ValueOf(src)
+	if source.Kind() == reflect.Ptr && !source.IsNil() {
+		source = source.Elem()
+	}
+	return mergeValues(state, target, source, cfg, "")
+}
+
+// MergeSlice merges the elements of src into the elements of *dst, which
+// must be structs. Elements are paired by index, or by the value of the
+// field named cfg.SliceKeyField if it is set. Each pair is merged as with
+// Merge, and source elements without a counterpart are appended to *dst.
+//
+// Errors are wrapped in a *FieldError whose path is the index of the source
+// element.
+func MergeSlice[T any](dst *[]T, src []T, cfg Config) error {
+	if dst == nil {
+		return ErrInvalidDestination
+	}
+
+	state := newMergeState(context.Background())
+	merge := func(i, j int) error {
+		err := mergeValues(state, reflect.ValueOf(&(*dst)[i]), reflect.ValueOf(src[j]), cfg, "")
+		if err != nil {
+			return &FieldError{Path: strconv.Itoa(j), Err: err}
+		}
+		return nil
+	}
+
+	if cfg.SliceKeyField == "" {
+		n := min(len(*dst), len(src))
+		for i := 0; i < n; i++ {
+			if err := merge(i, i); err != nil {
+				return err
+			}
+		}
+		*dst = append(*dst, src[n:]...)
+		return nil
+	}
+
+	keyOf := func(v T) (interface{}, error) {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Struct {
+			return nil, ErrInvalidDestination
+		}
+		key := rv.FieldByName(cfg.SliceKeyField)
+		if !key.IsValid() || !key.Type().Comparable() {
+			return nil, &FieldError{Path: cfg.SliceKeyField, Err: ErrInvalidPath}
+		}
+		return key.Interface(), nil
+	}
+
+	index := make(map[interface{}]int, len(*dst))
+	for i, v := range *dst {
+		key, err := keyOf(v)
+		if err != nil {
+			return err
+		}
+		if _, ok := index[key]; !ok {
+			index[key] = i
+		}
+	}
+
+	var extra []T
+	for j, v := range src {
+		key, err := keyOf(v)
+		if err != nil {
+			return err
+		}
+		i, ok := index[key]
+		if !ok {
+			extra = append(extra, v)
+			continue
+		}
+		if err := merge(i, j); err != nil {
+			return err
+		}
+	}
+	*dst = append(*dst, extra...)
+	return nil
+}
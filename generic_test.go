@@ -0,0 +1,263 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeTyped(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      TestStruct
+		src      TestStruct
+		cfg      []Config
+		expected TestStruct
+	}{
+		{
+			name: "Default config",
+			dst:  TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Old City"}},
+			src:  TestStruct{Name: "Bob", Address: Address{Street: "456 New St"}},
+			expected: TestStruct{
+				Name:    "Bob",
+				Address: Address{Street: "456 New St"},
+			},
+		},
+		{
+			name: "ExcludeEmpty",
+			dst:  TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Old City"}},
+			src:  TestStruct{Name: "Bob", Address: Address{Street: "456 New St"}},
+			cfg:  []Config{{Option: ExcludeEmpty}},
+			expected: TestStruct{
+				Name:    "Bob",
+				Age:     30,
+				Address: Address{Street: "456 New St", City: "Old City"},
+			},
+		},
+		{
+			name: "OverwriteEmpty",
+			dst:  TestStruct{Name: "Alice", Address: Address{City: "Old City"}},
+			src:  TestStruct{Name: "Bob", Age: 25, Address: Address{City: "New City", Country: "Uganda"}},
+			cfg:  []Config{{Option: OverwriteEmpty}},
+			expected: TestStruct{
+				Name:    "Alice",
+				Age:     25,
+				Address: Address{City: "Old City", Country: "Uganda"},
+			},
+		},
+		{
+			name: "Include nested path",
+			dst:  TestStruct{Name: "Alice", Address: Address{Street: "Old St", City: "Old City"}},
+			src:  TestStruct{Name: "Bob", Address: Address{Street: "New St", City: "New City"}},
+			cfg:  []Config{{Option: IncludeAll, Include: []string{"Address.City"}}},
+			expected: TestStruct{
+				Name:    "Alice",
+				Address: Address{Street: "Old St", City: "New City"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := MergeTyped(&tt.dst, tt.src, tt.cfg...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("MergeTyped() = %#v, want %#v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeTypedPointerFields(t *testing.T) {
+	dst := Person{Name: "Alice", Address: &Address{City: "Old City"}}
+	src := Person{Name: "Bob", Age: 30, Address: &Address{City: "New City"}}
+
+	if err := MergeTyped(&dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(dst, src) {
+		t.Errorf("expected %#v, got %#v", src, dst)
+	}
+}
+
+func TestMergeTypedNonStruct(t *testing.T) {
+	var dst int
+	err := MergeTyped(&dst, 10)
+	if !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	src := Team{Name: "Core", Lead: &Person{Name: "Alice", Address: &Address{City: "Kampala"}}}
+
+	cp := Clone(src)
+	if !reflect.DeepEqual(cp, src) {
+		t.Fatalf("Clone() = %+v, want %+v", cp, src)
+	}
+	cp.Lead.Address.City = "Gulu"
+	if src.Lead.Address.City != "Kampala" {
+		t.Error("mutating the clone changed the original")
+	}
+
+	ptr := Clone(&src)
+	if ptr == &src || ptr.Lead == src.Lead || !reflect.DeepEqual(*ptr, src) {
+		t.Errorf("Clone() = %+v, want a deep copy of %+v", ptr, src)
+	}
+}
+
+func TestClonePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != ErrInvalidSource {
+			t.Errorf("expected panic with ErrInvalidSource, got %v", r)
+		}
+	}()
+	Clone((*Person)(nil))
+}
+
+func TestMergeInto(t *testing.T) {
+	dst := Team{Name: "Core", Lead: &Person{Name: "Alice", Address: &Address{City: "Kampala"}}}
+	src := Team{Lead: &Person{Age: 30, Address: &Address{City: "Gulu"}}}
+
+	got, err := MergeInto(dst, src, Config{Option: ExcludeEmpty})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "Core" || got.Lead.Age != 30 || got.Lead.Address.City != "Gulu" {
+		t.Errorf("MergeInto() = %+v, want the merged team", got)
+	}
+	if dst.Lead.Age != 0 || dst.Lead.Address.City != "Kampala" {
+		t.Errorf("MergeInto() modified dst: %+v", dst.Lead)
+	}
+
+	ptr, err := MergeInto(&dst, &src, Config{Option: ExcludeEmpty})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ptr == &dst || ptr.Lead.Age != 30 || dst.Lead.Age != 0 {
+		t.Errorf("MergeInto() = %+v, want a merged copy of %+v", ptr, dst)
+	}
+
+	// Appending never writes to the spare capacity of dst's slices.
+	type Scores struct{ Values []int }
+	base := make([]int, 1, 4)
+	appended, err := MergeInto(Scores{Values: base}, Scores{Values: []int{7}}, Config{Option: AppendSlices})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(appended.Values, []int{0, 7}) || base[:2][1] != 0 {
+		t.Errorf("MergeInto() = %v, base = %v", appended.Values, base[:2])
+	}
+
+	if _, err := MergeInto((*Team)(nil), &src); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+	if _, err := MergeInto(&dst, nil); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
+
+func TestMergeAll(t *testing.T) {
+	defaults := Team{Name: "Core", Lead: &Person{Name: "Alice"}}
+	env := Team{Name: "Platform", Lead: &Person{Name: "Bob"}}
+	flags := Team{Name: "Infra", Members: []string{"Carol"}}
+
+	got, err := MergeAll(defaults, env, flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, flags) {
+		t.Errorf("MergeAll() = %+v, want %+v", got, flags)
+	}
+	if defaults.Name != "Core" || defaults.Lead.Name != "Alice" || env.Lead.Name != "Bob" {
+		t.Error("MergeAll() modified its arguments")
+	}
+
+	// Without overrides the result is a deep copy of base.
+	cp, err := MergeAll(&defaults)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cp == &defaults || cp.Lead == defaults.Lead || !reflect.DeepEqual(*cp, defaults) {
+		t.Errorf("MergeAll() = %+v, want a deep copy of %+v", cp, defaults)
+	}
+
+	// On error the overrides merged so far are kept.
+	partial, err := MergeAll(&defaults, &env, nil, &flags)
+	if err != ErrInvalidSource {
+		t.Fatalf("expected ErrInvalidSource, got %v", err)
+	}
+	if partial == nil || partial.Name != "Platform" {
+		t.Errorf("expected the partial result, got %+v", partial)
+	}
+}
+
+type Permission struct {
+	ID     int
+	Name   string
+	Active bool
+}
+
+func TestMergeSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      []Permission
+		src      []Permission
+		cfg      Config
+		expected []Permission
+	}{
+		{
+			name:     "By index",
+			dst:      []Permission{{ID: 1, Name: "read"}, {ID: 2, Name: "write"}},
+			src:      []Permission{{Active: true}},
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: []Permission{{ID: 1, Name: "read", Active: true}, {ID: 2, Name: "write"}},
+		},
+		{
+			name:     "By index appends extra source elements",
+			dst:      []Permission{{ID: 1, Name: "read"}},
+			src:      []Permission{{Name: "READ"}, {ID: 3, Name: "admin"}},
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: []Permission{{ID: 1, Name: "READ"}, {ID: 3, Name: "admin"}},
+		},
+		{
+			name:     "By key",
+			dst:      []Permission{{ID: 1, Name: "read"}, {ID: 2, Name: "write"}},
+			src:      []Permission{{ID: 2, Active: true}, {ID: 3, Name: "admin"}},
+			cfg:      Config{Option: ExcludeEmpty, SliceKeyField: "ID"},
+			expected: []Permission{{ID: 1, Name: "read"}, {ID: 2, Name: "write", Active: true}, {ID: 3, Name: "admin"}},
+		},
+		{
+			name:     "Nil destination",
+			src:      []Permission{{ID: 1}},
+			cfg:      Config{SliceKeyField: "ID"},
+			expected: []Permission{{ID: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := MergeSlice(&tt.dst, tt.src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("MergeSlice() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeSliceErrors(t *testing.T) {
+	dst := []Permission{{ID: 1}}
+	err := MergeSlice(&dst, []Permission{{ID: 1}}, Config{SliceKeyField: "Missing"})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+
+	ints := []int{1}
+	if err := MergeSlice(&ints, []int{2}, Config{}); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package structmerge
+
+import (
+	"path"
+	"strings"
+)
+
+// pattern is a compiled Include or Exclude path. Each segment is matched
+// against one path segment with path.Match, except for "**", which matches
+// any number of segments, including none.
+type pattern []string
+
+// compilePattern returns the compiled form of p. Patterns are compiled
+// once per merge, by newPathFilter, and not kept beyond it.
+func compilePattern(p string) pattern {
+	return pattern(strings.Split(p, "."))
+}
+
+// isGlob reports whether p contains wildcard characters.
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// match reports whether the pattern matches the path segments exactly.
+func (p pattern) match(segments []string) bool {
+	return matchSegments(p, segments, false, false)
+}
+
+// matchSegments matches pat against segments. If prefix is set, segments
+// may end before pat does (segments name a parent of a match); if nested is
+// set, pat may end before segments do (segments name a nested field of a
+// match).
+func matchSegments(pat, segments []string, prefix, nested bool) bool {
+	for len(pat) > 0 && len(segments) > 0 {
+		if pat[0] == "**" {
+			return matchSegments(pat[1:], segments, prefix, nested) ||
+				matchSegments(pat, segments[1:], prefix, nested)
+		}
+		if ok, _ := path.Match(pat[0], segments[0]); !ok {
+			return false
+		}
+		pat, segments = pat[1:], segments[1:]
+	}
+
+	if len(pat) == 0 {
+		return len(segments) == 0 || nested
+	}
+	return prefix || (len(pat) == 1 && pat[0] == "**")
+}
@@ -0,0 +1,182 @@
+package structmerge
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Shipment struct {
+	Name    string
+	Country string
+	From    Address
+	To      Address
+}
+
+func TestMergeWildcards(t *testing.T) {
+	dst := Shipment{
+		Name:    "old",
+		Country: "Kenya",
+		From:    Address{Street: "1 Old St", City: "Nairobi", Country: "Kenya"},
+		To:      Address{Street: "2 Old St", City: "Mombasa", Country: "Kenya"},
+	}
+	src := Shipment{
+		Name:    "new",
+		Country: "Uganda",
+		From:    Address{Street: "1 New St", City: "Kampala", Country: "Uganda"},
+		To:      Address{Street: "2 New St", City: "Gulu", Country: "Uganda"},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected Shipment
+	}{
+		{
+			name:     "Include *",
+			cfg:      Config{Include: []string{"*"}},
+			expected: src,
+		},
+		{
+			name: "Include Address.*",
+			cfg:  Config{Include: []string{"From.*"}},
+			expected: Shipment{
+				Name:    "old",
+				Country: "Kenya",
+				From:    src.From,
+				To:      dst.To,
+			},
+		},
+		{
+			name: "Include **.Country",
+			cfg:  Config{Include: []string{"**.Country"}},
+			expected: Shipment{
+				Name:    "old",
+				Country: "Uganda",
+				From:    Address{Street: "1 Old St", City: "Nairobi", Country: "Uganda"},
+				To:      Address{Street: "2 Old St", City: "Mombasa", Country: "Uganda"},
+			},
+		},
+		{
+			name: "Include *.City",
+			cfg:  Config{Include: []string{"*.City"}},
+			expected: Shipment{
+				Name:    "old",
+				Country: "Kenya",
+				From:    Address{Street: "1 Old St", City: "Kampala", Country: "Kenya"},
+				To:      Address{Street: "2 Old St", City: "Gulu", Country: "Kenya"},
+			},
+		},
+		{
+			name: "Exclude wildcard",
+			cfg:  Config{Exclude: []string{"*.Street", "Name"}},
+			expected: Shipment{
+				Name:    "old",
+				Country: "Uganda",
+				From:    Address{Street: "1 Old St", City: "Kampala", Country: "Uganda"},
+				To:      Address{Street: "2 Old St", City: "Gulu", Country: "Uganda"},
+			},
+		},
+		{
+			name: "Include and exclude wildcards",
+			cfg:  Config{Include: []string{"To.*", "Name"}, Exclude: []string{"**.Country"}},
+			expected: Shipment{
+				Name:    "new",
+				Country: "Kenya",
+				From:    dst.From,
+				To:      Address{Street: "2 New St", City: "Gulu", Country: "Kenya"},
+			},
+		},
+		{
+			name: "Segment pattern",
+			cfg:  Config{Include: []string{"F*.C*"}},
+			expected: Shipment{
+				Name:    "old",
+				Country: "Kenya",
+				From:    Address{Street: "1 Old St", City: "Kampala", Country: "Uganda"},
+				To:      dst.To,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeSelectThroughPointer(t *testing.T) {
+	dst := Team{Name: "old", Lead: &Person{Name: "Alice", Age: 30}}
+	src := Team{Name: "new", Lead: &Person{Name: "Bob", Age: 40}}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		dst      Team
+		expected Team
+	}{
+		{
+			name:     "Glob",
+			cfg:      Config{Include: []string{"Lead.N*"}},
+			dst:      dst,
+			expected: Team{Name: "old", Lead: &Person{Name: "Bob", Age: 30}},
+		},
+		{
+			name:     "IncludeFunc",
+			cfg:      Config{IncludeFunc: func(p string) bool { return p == "Lead.Name" }},
+			dst:      dst,
+			expected: Team{Name: "old", Lead: &Person{Name: "Bob", Age: 30}},
+		},
+		{
+			name:     "IncludeFunc into nil pointer",
+			cfg:      Config{IncludeFunc: func(p string) bool { return p == "Lead.Name" }},
+			expected: Team{Lead: &Person{Name: "Bob"}},
+		},
+		{
+			name:     "IncludeFunc selecting nothing",
+			cfg:      Config{IncludeFunc: func(p string) bool { return p == "Name" }},
+			expected: Team{Name: "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.dst
+			if got.Lead != nil {
+				lead := *got.Lead
+				got.Lead = &lead
+			}
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %+v %+v, want %+v %+v", got, got.Lead, tt.expected, tt.expected.Lead)
+			}
+		})
+	}
+}
+
+func TestPathFilterCompilesPatterns(t *testing.T) {
+	f := newPathFilter(Config{Include: []string{"Address.*"}, Exclude: []string{"**.Zip"}})
+	if !reflect.DeepEqual(f.includeGlob, []pattern{{"Address", "*"}}) {
+		t.Errorf("includeGlob = %v", f.includeGlob)
+	}
+	if !reflect.DeepEqual(f.excludeGlob, []pattern{{"**", "Zip"}}) {
+		t.Errorf("excludeGlob = %v", f.excludeGlob)
+	}
+}
+
+func TestValidateConfigWildcards(t *testing.T) {
+	if err := ValidateConfig(Config{Include: []string{"From.*", "**.City"}}, Shipment{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateConfig(Config{Include: []string{"Form.*"}}, Shipment{}); err == nil {
+		t.Error("expected an error for an invalid prefix")
+	}
+}
@@ -0,0 +1,165 @@
+package structmerge
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Hash returns a 64-bit FNV-1a hash of the struct src, or of the struct a
+// pointer src points to, for use as a cache key or to detect changes.
+//
+// Fields are hashed by the rules Equal compares them with, so structs that
+// are Equal have the same hash: time.Time values are hashed by instant, nil
+// and empty values alike, and unexported fields and fields tagged with
+// `merge:"-"` are ignored. Map entries are hashed independently of their
+// order, and pointers that lead back to a value being hashed are not
+// followed again.
+func Hash(src interface{}) (uint64, error) {
+	v, err := structValue(src)
+	if err != nil {
+		return 0, err
+	}
+
+	h := hasher{Hash64: fnv.New64a(), visited: make(map[uintptr]bool)}
+	h.hashStruct(v)
+	return h.Sum64(), nil
+}
+
+type hasher struct {
+	hash.Hash64
+	visited map[uintptr]bool // pointers being hashed
+	buf     [8]byte
+}
+
+func (h *hasher) writeUint(n uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], n)
+	h.Write(h.buf[:])
+}
+
+func (h *hasher) hashStruct(v reflect.Value) {
+	for _, field := range cachedMeta(v.Type()).fields {
+		if field.exported {
+			h.hashField(v.FieldByIndex(field.index))
+		}
+	}
+}
+
+// hashField hashes a field as Equal compares it.
+func (h *hasher) hashField(v reflect.Value) {
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			h.writeUint(0)
+		} else {
+			h.writeUint(1)
+			h.writeUint(uint64(t.UnixNano()))
+		}
+		return
+	}
+
+	switch {
+	case isNestedStruct(v.Type()):
+		h.hashStruct(v)
+		return
+	case isStructPointer(v.Type()) && !v.IsNil():
+		h.hashPointer(v, func() { h.hashField(v.Elem()) })
+		return
+	}
+
+	// Values that are both empty are equal, whatever their kind.
+	if isZero(v) {
+		h.writeUint(0)
+		return
+	}
+	h.writeUint(1)
+	h.hashValue(v)
+}
+
+// hashValue hashes any value, including unexported struct fields.
+func (h *hasher) hashValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.writeUint(1)
+		} else {
+			h.writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		h.writeUint(math.Float64bits(real(c)))
+		h.writeUint(math.Float64bits(imag(c)))
+	case reflect.String:
+		h.writeUint(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Slice, reflect.Array:
+		h.writeUint(uint64(v.Len()))
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			h.Write(v.Bytes())
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			h.hashValue(v.Index(i))
+		}
+	case reflect.Map:
+		// Combine the entry hashes with a sum so that the order of
+		// iteration does not matter.
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := hasher{Hash64: fnv.New64a(), visited: h.visited}
+			entry.hashValue(iter.Key())
+			entry.hashValue(iter.Value())
+			sum += entry.Sum64()
+		}
+		h.writeUint(uint64(v.Len()))
+		h.writeUint(sum)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			h.hashValue(v.Field(i))
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			h.writeUint(0)
+			return
+		}
+		h.hashPointer(v, func() { h.hashField(v.Elem()) })
+	case reflect.Interface:
+		if v.IsNil() {
+			h.writeUint(0)
+			return
+		}
+		// Hash the value as Equal compares it, e.g. times by instant.
+		h.Write([]byte(v.Elem().Type().String()))
+		h.hashField(v.Elem())
+	default:
+		// Functions, channels and unsafe pointers are hashed by nil-ness.
+		if v.IsNil() {
+			h.writeUint(0)
+		} else {
+			h.writeUint(1)
+		}
+	}
+}
+
+// hashPointer calls hash for the non-nil pointer v unless v is already being
+// hashed.
+func (h *hasher) hashPointer(v reflect.Value, hash func()) {
+	addr := v.Pointer()
+	if h.visited[addr] {
+		h.writeUint(2)
+		return
+	}
+	h.visited[addr] = true
+	defer delete(h.visited, addr)
+	hash()
+}
@@ -0,0 +1,143 @@
+package structmerge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	base := Event{
+		Name:     "Launch",
+		Location: Location{Address: Address{City: "Kampala"}},
+		Host:     &Person{Name: "Alice"},
+		Start:    start,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(e *Event)
+		same   bool
+	}{
+		{"Identical", func(e *Event) {}, true},
+		{"Same instant in another zone", func(e *Event) { e.Start = start.In(time.FixedZone("EAT", 3*3600)) }, true},
+		{"Distinct but equal pointers", func(e *Event) { e.Host = &Person{Name: "Alice"} }, true},
+		{"Nil and empty slice", func(e *Event) { e.Tags = []string{} }, true},
+		{"Ignored fields", func(e *Event) { e.secret, e.Internal = "x", "y" }, true},
+		{"Nested field", func(e *Event) { e.Location.Address.City = "Gulu" }, false},
+		{"Pointer field", func(e *Event) { e.Host = &Person{Name: "Bob"} }, false},
+		{"Nil pointer", func(e *Event) { e.Host = nil }, false},
+		{"Slice", func(e *Event) { e.Tags = []string{"go"} }, false},
+		{"Time", func(e *Event) { e.Start = start.Add(time.Hour) }, false},
+	}
+
+	want, err := Hash(base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.modify(&other)
+
+			got, err := Hash(&other)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == want) != tt.same {
+				t.Errorf("Hash() = %x, base hash %x; want same = %v", got, want, tt.same)
+			}
+		})
+	}
+}
+
+func TestHashValues(t *testing.T) {
+	type Blob struct {
+		Data   []byte
+		Labels map[string]int
+		Grid   [2]int
+	}
+
+	base := Blob{Data: []byte("abc"), Labels: map[string]int{"a": 1, "b": 2, "c": 3}, Grid: [2]int{1, 2}}
+	want, _ := Hash(base)
+
+	same := Blob{Data: []byte("abc"), Labels: map[string]int{"c": 3, "b": 2, "a": 1}, Grid: [2]int{1, 2}}
+	if got, _ := Hash(same); got != want {
+		t.Errorf("expected equal blobs to hash alike, got %x and %x", got, want)
+	}
+
+	for _, other := range []Blob{
+		{Data: []byte("abd"), Labels: base.Labels, Grid: base.Grid},
+		{Data: base.Data, Labels: map[string]int{"a": 1, "b": 3, "c": 2}, Grid: base.Grid},
+		{Data: base.Data, Labels: base.Labels, Grid: [2]int{2, 1}},
+	} {
+		if got, _ := Hash(other); got == want {
+			t.Errorf("expected %+v to hash differently from %+v", other, base)
+		}
+	}
+}
+
+func TestHashInterfaceTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := Reading{Value: now}
+	b := Reading{Value: now.In(time.FixedZone("EAT", 3*60*60))}
+
+	if equal, _ := Equal(a, b); !equal {
+		t.Fatal("expected readings of the same instant to be Equal")
+	}
+	ha, _ := Hash(a)
+	hb, _ := Hash(b)
+	if ha != hb {
+		t.Errorf("expected Equal readings to hash alike, got %x and %x", ha, hb)
+	}
+	if hc, _ := Hash(Reading{Value: now.Add(time.Second)}); hc == ha {
+		t.Error("expected a different instant to hash differently")
+	}
+}
+
+func TestHashTimePointer(t *testing.T) {
+	type Stamp struct{ T *time.Time }
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	local := now.In(time.FixedZone("EAT", 3*60*60))
+	a, b := Stamp{&now}, Stamp{&local}
+
+	if equal, _ := Equal(a, b); !equal {
+		t.Fatal("expected stamps of the same instant to be Equal")
+	}
+	ha, _ := Hash(a)
+	hb, _ := Hash(b)
+	if ha != hb {
+		t.Errorf("expected Equal stamps to hash alike, got %x and %x", ha, hb)
+	}
+}
+
+func TestHashCycles(t *testing.T) {
+	a := &Node{Name: "a"}
+	a.Next = a
+	b := &Node{Name: "a"}
+	b.Next = b
+
+	ha, err := Hash(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hb, _ := Hash(b); ha != hb {
+		t.Errorf("expected equal cycles to hash alike, got %x and %x", ha, hb)
+	}
+
+	b.Next = &Node{Name: "b", Next: b}
+	if hb, _ := Hash(b); ha == hb {
+		t.Errorf("expected different cycles to hash differently")
+	}
+}
+
+func TestHashErrors(t *testing.T) {
+	if _, err := Hash(42); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+	if _, err := Hash((*Event)(nil)); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// JSONPatchOperation is a single operation of an RFC 6902 JSON Patch.
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Replacers for the escape sequences of JSON Pointer segments (RFC 6901).
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// CreateJSONPatch returns an RFC 6902 JSON Patch document that turns from
+// into to, which must be structs (or pointers to structs) of the same type.
+//
+// Each field reported by Diff becomes one operation: "add" if it is empty in
+// from, "remove" if it is empty in to, and "replace" otherwise. Paths are
+// JSON Pointers built from the `json` tag names of the fields, falling back
+// to their Go names, e.g. "/address/street".
+func CreateJSONPatch(from, to interface{}) ([]byte, error) {
+	paths, err := Diff(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromValue, _ := structValue(from)
+	toValue, _ := structValue(to)
+
+	ops := make([]JSONPatchOperation, 0, len(paths))
+	for _, path := range paths {
+		oldVal, err := lookupPath(fromValue, path, false)
+		if err != nil {
+			return nil, err
+		}
+		newVal, err := lookupPath(toValue, path, false)
+		if err != nil {
+			return nil, err
+		}
+
+		op := JSONPatchOperation{Op: "replace", Path: jsonPointer(fromValue.Type(), path)}
+		switch {
+		case !newVal.IsValid() || isZero(newVal):
+			op.Op = "remove"
+		case !oldVal.IsValid() || isZero(oldVal):
+			op.Op = "add"
+		}
+
+		if op.Op != "remove" {
+			if op.Value, err = json.Marshal(newVal.Interface()); err != nil {
+				return nil, &FieldError{Path: path, Err: err}
+			}
+		}
+		ops = append(ops, op)
+	}
+	return json.Marshal(ops)
+}
+
+// ApplyJSONPatch applies the RFC 6902 JSON Patch document patch to dst, which
+// must be a pointer to a struct. The "add" and "replace" operations set a
+// field to the given value and "remove" resets it to its zero value. Path
+// segments may use `json` tag names or Go field names. Other operations fail
+// with ErrUnsupportedOperation and unknown paths with ErrInvalidPath; dst is
+// left untouched in both cases.
+func ApplyJSONPatch(dst interface{}, patch []byte) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	var ops []JSONPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return err
+	}
+
+	t := dstValue.Elem().Type()
+	p := &Patch{Changes: make(map[string]interface{}, len(ops))}
+	for _, op := range ops {
+		path, err := fromJSONPointer(t, op.Path)
+		if err != nil {
+			return err
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			p.Changes[path] = op.Value
+		case "remove":
+			p.Changes[path] = nil
+		default:
+			return &FieldError{Path: op.Path, Err: ErrUnsupportedOperation}
+		}
+	}
+	return ApplyPatch(dstValue.Interface(), p)
+}
+
+// jsonPointer converts the dot-separated path of a field of the struct type t
+// into a JSON Pointer.
+func jsonPointer(t reflect.Type, path string) string {
+	var b strings.Builder
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		field, _ := t.FieldByName(name)
+		if json := jsonName(field); json != "" {
+			name = json
+		}
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(name))
+		t = field.Type
+	}
+	return b.String()
+}
+
+// fromJSONPointer converts a JSON Pointer into the dot-separated path of a
+// field of the struct type t.
+func fromJSONPointer(t reflect.Type, pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return "", &FieldError{Path: pointer, Err: ErrInvalidPath}
+	}
+
+	segments := strings.Split(pointer[1:], "/")
+	for i, segment := range segments {
+		segments[i] = pointerUnescaper.Replace(segment)
+	}
+
+	path := resolvePath(t, strings.Join(segments, "."), true, nil)
+	if err := checkPath(t, path); err != nil {
+		return "", &FieldError{Path: pointer, Err: ErrInvalidPath}
+	}
+	return path, nil
+}
@@ -0,0 +1,123 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Profile struct {
+	Name    string       `json:"name"`
+	Email   string       `json:"email"`
+	Tags    []string     `json:"tags"`
+	Address JSONAddress  `json:"address"`
+	Home    *JSONAddress `json:"home"`
+	Ratio   float64      `json:"a/b"`
+}
+
+func TestCreateJSONPatch(t *testing.T) {
+	from := Profile{Name: "Alice", Email: "a@x.io", Address: JSONAddress{Street: "Old St"}}
+	to := Profile{Name: "Bob", Tags: []string{"x"}, Address: JSONAddress{Street: "New St"}, Ratio: 0.5}
+
+	data, err := CreateJSONPatch(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ops []JSONPatchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		t.Fatalf("invalid patch %s: %v", data, err)
+	}
+
+	expected := []JSONPatchOperation{
+		{Op: "replace", Path: "/name", Value: json.RawMessage(`"Bob"`)},
+		{Op: "remove", Path: "/email"},
+		{Op: "add", Path: "/tags", Value: json.RawMessage(`["x"]`)},
+		{Op: "replace", Path: "/address/street", Value: json.RawMessage(`"New St"`)},
+		{Op: "add", Path: "/a~1b", Value: json.RawMessage(`0.5`)},
+	}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("CreateJSONPatch() = %s", data)
+	}
+}
+
+func TestJSONPatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to Profile
+	}{
+		{
+			name: "Replace and remove",
+			from: Profile{Name: "Alice", Email: "a@x.io", Tags: []string{"a"}},
+			to:   Profile{Name: "Bob", Tags: []string{"b", "c"}},
+		},
+		{
+			name: "Nested fields",
+			from: Profile{Address: JSONAddress{Street: "Old St", PostalCode: "111"}},
+			to:   Profile{Address: JSONAddress{Street: "New St", PostalCode: "111"}, Ratio: 2},
+		},
+		{
+			name: "Pointer fields",
+			from: Profile{Home: &JSONAddress{Street: "Old St"}},
+			to:   Profile{Home: &JSONAddress{Street: "New St", PostalCode: "222"}},
+		},
+		{
+			name: "Add and remove pointer",
+			from: Profile{Name: "Alice"},
+			to:   Profile{Name: "Alice", Home: &JSONAddress{Street: "New St"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := CreateJSONPatch(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := tt.from
+			if err := ApplyJSONPatch(&got, patch); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.to) {
+				t.Errorf("got %+v, want %+v (patch %s)", got, tt.to, patch)
+			}
+
+			back, err := CreateJSONPatch(tt.to, tt.from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := ApplyJSONPatch(&got, back); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.from) {
+				t.Errorf("reverse got %+v, want %+v (patch %s)", got, tt.from, back)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		err   error
+	}{
+		{"Unknown path", `[{"op":"replace","path":"/nmae","value":"x"}]`, ErrInvalidPath},
+		{"Relative path", `[{"op":"replace","path":"name","value":"x"}]`, ErrInvalidPath},
+		{"Unsupported op", `[{"op":"move","path":"/name"}]`, ErrUnsupportedOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Profile{Name: "Alice"}
+			if err := ApplyJSONPatch(&got, []byte(tt.patch)); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+			if got.Name != "Alice" {
+				t.Errorf("destination modified: %+v", got)
+			}
+		})
+	}
+}
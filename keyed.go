@@ -0,0 +1,183 @@
+package structmerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MergeByKey merges the elements of src into the elements of *dst that have
+// the same key, as returned by key. T must be a struct or a pointer to a
+// struct. Matching pairs are merged as with Merge and source elements with
+// a new key are appended to *dst. A key appearing twice in either slice
+// fails with ErrDuplicateKey, leaving *dst untouched.
+//
+// Include and Exclude paths refer to the fields of the elements. Errors are
+// wrapped in a *FieldError whose path is the key of the element.
+//
+// If key is nil, the key is read from the fields tagged `merge:"key"`, as
+// found by KeyFieldOf.
+//
+// The `merge:"key:Field"` struct tag gives a slice field the same behaviour
+// during Merge, using the value of Field as key.
+func MergeByKey[T any](dst *[]T, src []T, key func(T) string, cfg ...Config) error {
+	if dst == nil {
+		return ErrInvalidDestination
+	}
+
+	keyOf := func(v reflect.Value) (string, error) {
+		return key(v.Interface().(T)), nil
+	}
+	if key == nil {
+		name, err := KeyFieldOf(reflect.TypeOf((*T)(nil)).Elem())
+		if err != nil {
+			return err
+		}
+		keyOf = fieldKey(name)
+	}
+	state := newMergeState(context.Background())
+	return mergeByKey(state, reflect.ValueOf(dst).Elem(), reflect.ValueOf(src), keyOf, configOf(cfg), "")
+}
+
+// KeyFieldOf returns the name of the field of the struct type t, or of the
+// struct t points to, tagged `merge:"key"`. The names of several key fields
+// are joined with ":" and form a composite key, whose value is the values of
+// the fields joined with ":".
+//
+// A type without key fields yields ErrInvalidPath; a key field that is itself
+// a struct merged field by field is not supported and yields a *FieldError
+// wrapping ErrTypeMismatch.
+func KeyFieldOf(t reflect.Type) (string, error) {
+	t, err := typeOf(t)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, field := range cachedMeta(t).fields {
+		if !field.opts.Contains("key") {
+			continue
+		}
+		if field.nested || isStructPointer(t.FieldByIndex(field.index).Type) {
+			return "", &FieldError{Path: field.name, Err: ErrTypeMismatch}
+		}
+		names = append(names, field.name)
+	}
+
+	if len(names) == 0 {
+		return "", &MergeError{Code: ErrCodeInvalidPath, Cause: errors.New(`no field tagged merge:"key"`)}
+	}
+	return strings.Join(names, ":"), nil
+}
+
+// fieldKey returns a key function reading the field named name of a struct
+// or pointer to a struct. A name listing several fields separated by ":"
+// reads them all and joins their values with ":".
+func fieldKey(name string) func(v reflect.Value) (string, error) {
+	names := strings.Split(name, ":")
+	return func(v reflect.Value) (string, error) {
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return "", ErrInvalidSource
+		}
+
+		values := make([]string, len(names))
+		for i, name := range names {
+			field := v.FieldByName(name)
+			if !field.IsValid() {
+				return "", &FieldError{Path: name, Err: ErrInvalidPath}
+			}
+			values[i] = fmt.Sprint(field.Interface())
+		}
+		return strings.Join(values, ":"), nil
+	}
+}
+
+// mergeByKey merges the elements of the src slice into the elements of the
+// settable dst slice with the same key. The result is stored in a new slice,
+// and pointer elements are merged into copies, so values shared with dst are
+// never modified. Fields of the elements have the path "<path>.<key>.<field>".
+func mergeByKey(state *mergeState, dst, src reflect.Value, keyOf func(reflect.Value) (string, error), cfg Config, path string) error {
+	merged := reflect.MakeSlice(dst.Type(), dst.Len(), dst.Len()+src.Len())
+	reflect.Copy(merged, dst)
+
+	index := make(map[string]int, merged.Len())
+	for i := 0; i < merged.Len(); i++ {
+		key, err := keyOf(merged.Index(i))
+		if err != nil {
+			return err
+		}
+		if _, ok := index[key]; ok {
+			return &FieldError{Path: elementPath(path, key), Err: ErrDuplicateKey}
+		}
+		index[key] = i
+	}
+
+	seen := make(map[string]bool, src.Len())
+	for j := 0; j < src.Len(); j++ {
+		srcElem := src.Index(j)
+		key, err := keyOf(srcElem)
+		if err != nil {
+			return err
+		}
+		if seen[key] {
+			return &FieldError{Path: elementPath(path, key), Err: ErrDuplicateKey}
+		}
+		seen[key] = true
+
+		i, ok := index[key]
+		if !ok {
+			merged = reflect.Append(merged, srcElem)
+			continue
+		}
+
+		if err := mergeElement(state, merged.Index(i), srcElem, cfg, path, key); err != nil {
+			return err
+		}
+	}
+
+	dst.Set(merged)
+	return nil
+}
+
+// mergeElement merges srcElem into the slice element elem, a struct or a
+// pointer to a struct, whose key is key. Pointer elements are merged into a
+// copy. At the top level (an empty path) field paths are relative to the
+// element and errors are wrapped in a *FieldError naming the key.
+func mergeElement(state *mergeState, elem, srcElem reflect.Value, cfg Config, path, key string) error {
+	prefix := ""
+	if path != "" {
+		prefix = elementPath(path, key) + "."
+	}
+
+	var err error
+	switch {
+	case elem.Kind() != reflect.Ptr:
+		err = mergeValues(state, elem.Addr(), srcElem, cfg, prefix)
+	case elem.IsNil():
+		elem.Set(srcElem)
+	case !srcElem.IsNil():
+		ptr := reflect.New(elem.Type().Elem())
+		ptr.Elem().Set(elem.Elem())
+		if err = mergeValues(state, ptr, srcElem.Elem(), cfg, prefix); err == nil {
+			elem.Set(ptr)
+		}
+	}
+
+	if err != nil && path == "" {
+		return &FieldError{Path: key, Err: err}
+	}
+	return err
+}
+
+// elementPath returns the path of the slice element with the given key.
+func elementPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,213 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type User struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func userKey(u User) string { return strconv.Itoa(u.ID) }
+
+func TestMergeByKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      []User
+		src      []User
+		cfg      []Config
+		expected []User
+	}{
+		{
+			name:     "Matched",
+			dst:      []User{{ID: 1, Name: "Alice", Email: "a@x.io"}, {ID: 2, Name: "Bob"}},
+			src:      []User{{ID: 2, Email: "b@x.io"}},
+			cfg:      []Config{{Option: ExcludeEmpty}},
+			expected: []User{{ID: 1, Name: "Alice", Email: "a@x.io"}, {ID: 2, Name: "Bob", Email: "b@x.io"}},
+		},
+		{
+			name:     "Unmatched destination elements are kept",
+			dst:      []User{{ID: 1, Name: "Alice"}},
+			src:      nil,
+			expected: []User{{ID: 1, Name: "Alice"}},
+		},
+		{
+			name:     "New from source",
+			dst:      []User{{ID: 1, Name: "Alice"}},
+			src:      []User{{ID: 3, Name: "Carol"}, {ID: 1, Name: "Alicia"}},
+			expected: []User{{ID: 1, Name: "Alicia"}, {ID: 3, Name: "Carol"}},
+		},
+		{
+			name:     "Include applies to element fields",
+			dst:      []User{{ID: 1, Name: "Alice", Email: "a@x.io"}},
+			src:      []User{{ID: 1, Name: "Alicia", Email: "new@x.io"}},
+			cfg:      []Config{{Include: []string{"Email"}}},
+			expected: []User{{ID: 1, Name: "Alice", Email: "new@x.io"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := MergeByKey(&tt.dst, tt.src, userKey, tt.cfg...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("MergeByKey() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeByKeyPointers(t *testing.T) {
+	shared := &User{ID: 1, Name: "Alice"}
+	dst := []*User{shared}
+
+	if err := MergeByKey(&dst, []*User{{ID: 1, Email: "a@x.io"}}, func(u *User) string { return userKey(*u) }, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (User{ID: 1, Name: "Alice", Email: "a@x.io"}); *dst[0] != expected {
+		t.Errorf("MergeByKey() = %+v, want %+v", *dst[0], expected)
+	}
+	if shared.Email != "" {
+		t.Error("shared element modified")
+	}
+}
+
+func TestMergeByKeyDuplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst, src []User
+	}{
+		{"In destination", []User{{ID: 1}, {ID: 1}}, nil},
+		{"In source", []User{{ID: 1}}, []User{{ID: 2}, {ID: 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := append([]User(nil), tt.dst...)
+			err := MergeByKey(&tt.dst, tt.src, userKey)
+			if !errors.Is(err, ErrDuplicateKey) {
+				t.Fatalf("expected ErrDuplicateKey, got %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, before) {
+				t.Errorf("destination modified: %+v", tt.dst)
+			}
+		})
+	}
+}
+
+type Directory struct {
+	Users  []User  `merge:"key:ID"`
+	Admins []*User `merge:"omitempty,key:ID"`
+}
+
+func TestMergeKeyTag(t *testing.T) {
+	dst := Directory{
+		Users:  []User{{ID: 1, Name: "Alice", Email: "a@x.io"}, {ID: 2, Name: "Bob"}},
+		Admins: []*User{{ID: 1, Name: "Alice"}},
+	}
+	src := Directory{
+		Users:  []User{{ID: 2, Name: "Robert"}, {ID: 3, Name: "Carol"}},
+		Admins: []*User{{ID: 1, Email: "root@x.io"}},
+	}
+
+	if err := Merge(&dst, src, Config{Option: ExcludeEmpty, Exclude: []string{"Users.2.Name"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Directory{
+		Users:  []User{{ID: 1, Name: "Alice", Email: "a@x.io"}, {ID: 2, Name: "Bob"}, {ID: 3, Name: "Carol"}},
+		Admins: []*User{{ID: 1, Name: "Alice", Email: "root@x.io"}},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %+v, want %+v", dst, expected)
+	}
+
+	dst = Directory{Users: []User{{ID: 1}}}
+	err := Merge(&dst, Directory{Users: []User{{ID: 1}, {ID: 1}}})
+	var fieldErr *FieldError
+	if !errors.Is(err, ErrDuplicateKey) || !errors.As(err, &fieldErr) || fieldErr.Path != "Users.1" {
+		t.Errorf("expected ErrDuplicateKey at Users.1, got %v", err)
+	}
+}
+
+type Member struct {
+	Email string `merge:"key"`
+	Name  string
+}
+
+type Grant struct {
+	Tenant string `merge:"key"`
+	UserID int    `merge:"key"`
+	Role   string
+}
+
+func TestKeyFieldOf(t *testing.T) {
+	type NoKey struct {
+		Name string
+	}
+	type NestedKey struct {
+		Owner Address `merge:"key"`
+	}
+	type DeepKey struct {
+		Inner Member
+	}
+
+	tests := []struct {
+		name     string
+		typ      reflect.Type
+		expected string
+		err      error
+	}{
+		{"Single key", reflect.TypeOf(Member{}), "Email", nil},
+		{"Pointer type", reflect.TypeOf(&Member{}), "Email", nil},
+		{"Composite key", reflect.TypeOf(Grant{}), "Tenant:UserID", nil},
+		{"Missing key", reflect.TypeOf(NoKey{}), "", ErrInvalidPath},
+		{"Key in nested struct", reflect.TypeOf(DeepKey{}), "", ErrInvalidPath},
+		{"Struct key", reflect.TypeOf(NestedKey{}), "", ErrTypeMismatch},
+		{"Not a struct", reflect.TypeOf(42), "", ErrInvalidSource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := KeyFieldOf(tt.typ)
+			if name != tt.expected || !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Errorf("KeyFieldOf() = %q, %v; want %q, %v", name, err, tt.expected, tt.err)
+			}
+		})
+	}
+}
+
+func TestMergeByKeyTaggedFields(t *testing.T) {
+	members := []Member{{Email: "a@x.io", Name: "Alice"}, {Email: "b@x.io", Name: "Bob"}}
+	if err := MergeByKey(&members, []Member{{Email: "b@x.io", Name: "Robert"}, {Email: "c@x.io"}}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Member{{Email: "a@x.io", Name: "Alice"}, {Email: "b@x.io", Name: "Robert"}, {Email: "c@x.io"}}
+	if !reflect.DeepEqual(members, expected) {
+		t.Errorf("MergeByKey() = %+v, want %+v", members, expected)
+	}
+
+	grants := []*Grant{{Tenant: "acme", UserID: 1, Role: "viewer"}, {Tenant: "globex", UserID: 1, Role: "admin"}}
+	src := []*Grant{{Tenant: "acme", UserID: 1, Role: "editor"}, {Tenant: "acme", UserID: 2, Role: "viewer"}}
+	if err := MergeByKey(&grants, src, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	roles := make([]string, len(grants))
+	for i, g := range grants {
+		roles[i] = g.Tenant + "/" + strconv.Itoa(g.UserID) + "=" + g.Role
+	}
+	if want := []string{"acme/1=editor", "globex/1=admin", "acme/2=viewer"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("MergeByKey() = %v, want %v", roles, want)
+	}
+
+	users := []User{{ID: 1}}
+	if err := MergeByKey(&users, []User{{ID: 1}}, nil); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+}
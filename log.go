@@ -0,0 +1,163 @@
+package structmerge
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// redacted replaces the values of masked fields in log records.
+const redacted = "[REDACTED]"
+
+// logField emits a debug record to cfg.Logger describing what the merge did
+// to the field at path: "set", "append", "merge", "default" or "skip".
+// oldVal and newVal are logged when they can be shown as plain values.
+func (s *mergeState) logField(cfg Config, path, action string, oldVal, newVal reflect.Value) {
+	if cfg.Logger == nil || !cfg.Logger.Enabled(s.ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("path", path),
+		slog.String("action", action),
+		slog.Any("option", cfg.Option),
+	}
+	if v, ok := logValue(oldVal); ok {
+		attrs = append(attrs, slog.Attr{Key: "old", Value: v})
+	}
+	if v, ok := logValue(newVal); ok {
+		attrs = append(attrs, slog.Attr{Key: "new", Value: v})
+	}
+	cfg.Logger.LogAttrs(s.ctx, slog.LevelDebug, "merge field", attrs...)
+}
+
+// logMasked emits a debug record for the masked field at path, which is
+// always skipped, without revealing its values.
+func (s *mergeState) logMasked(cfg Config, path string) {
+	if cfg.Logger == nil || !cfg.Logger.Enabled(s.ctx, slog.LevelDebug) {
+		return
+	}
+	cfg.Logger.LogAttrs(s.ctx, slog.LevelDebug, "merge field",
+		slog.String("path", path),
+		slog.String("action", "skip"),
+		slog.Any("option", cfg.Option),
+		slog.String("old", redacted),
+		slog.String("new", redacted),
+	)
+}
+
+// logSkipped emits a debug record for the field at path, skipped for the
+// given reason rather than because of the merge option.
+func (s *mergeState) logSkipped(cfg Config, path, reason string) {
+	if cfg.Logger == nil || !cfg.Logger.Enabled(s.ctx, slog.LevelDebug) {
+		return
+	}
+	cfg.Logger.LogAttrs(s.ctx, slog.LevelDebug, "merge field",
+		slog.String("path", path),
+		slog.String("action", "skip"),
+		slog.Any("option", cfg.Option),
+		slog.String("reason", reason),
+	)
+}
+
+// logTruncated emits a warning to cfg.Logger for the field at path, whose
+// source value lost its fractional part when converted to an integer.
+func (s *mergeState) logTruncated(cfg Config, path string, oldVal, newVal reflect.Value) {
+	if cfg.Logger == nil || !cfg.Logger.Enabled(s.ctx, slog.LevelWarn) {
+		return
+	}
+	cfg.Logger.LogAttrs(s.ctx, slog.LevelWarn, "truncated number",
+		slog.String("path", path),
+		slog.Any("old", oldVal.Interface()),
+		slog.Any("new", newVal.Interface()),
+	)
+}
+
+// maxPathWarnings bounds the number of paths remembered by pathWarnings.
+const maxPathWarnings = 1024
+
+// pathWarnings records the paths already checked by warnUnknownPaths, so
+// that each is only checked once. It is cleared when it reaches
+// maxPathWarnings entries, so paths built at run time cannot grow it
+// without bound.
+var pathWarnings = struct {
+	sync.Mutex
+	seen map[pathWarningKey]bool
+}{seen: make(map[pathWarningKey]bool)}
+
+type pathWarningKey struct {
+	typ        reflect.Type
+	logger     *slog.Logger
+	list, path string
+}
+
+// checkedPath reports whether the path has already been checked for the
+// type and logger, recording it if not.
+func checkedPath(key pathWarningKey) bool {
+	pathWarnings.Lock()
+	defer pathWarnings.Unlock()
+
+	if pathWarnings.seen[key] {
+		return true
+	}
+	if len(pathWarnings.seen) >= maxPathWarnings {
+		pathWarnings.seen = make(map[pathWarningKey]bool)
+	}
+	pathWarnings.seen[key] = true
+	return false
+}
+
+// warnUnknownPaths emits a warning to cfg.Logger for every path in
+// cfg.Include and cfg.Exclude that names no field of the struct type t.
+// Each path is checked once per type, logger and list.
+func (s *mergeState) warnUnknownPaths(cfg Config, t reflect.Type) {
+	if cfg.Logger == nil || len(cfg.Include)+len(cfg.Exclude) == 0 ||
+		!cfg.Logger.Enabled(s.ctx, slog.LevelWarn) {
+		return
+	}
+
+	for _, list := range []struct {
+		name  string
+		paths []string
+	}{{"Include", cfg.Include}, {"Exclude", cfg.Exclude}} {
+		for _, path := range list.paths {
+			if checkedPath(pathWarningKey{t, cfg.Logger, list.name, path}) {
+				continue
+			}
+			if checkPath(t, path) != nil {
+				cfg.Logger.LogAttrs(s.ctx, slog.LevelWarn, "unknown merge path",
+					slog.String("path", path),
+					slog.String("list", list.name),
+					slog.String("type", t.String()),
+				)
+			}
+		}
+	}
+}
+
+// logValue returns v as a log value if it is a boolean, a number, a string
+// or implements fmt.Stringer. Other values, such as structs, slices and
+// maps, are not logged.
+func logValue(v reflect.Value) (slog.Value, bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return slog.Value{}, false
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return slog.AnyValue(v.Interface()), true
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return slog.Value{}, false
+		}
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return slog.StringValue(s.String()), true
+	}
+	return slog.Value{}, false
+}
@@ -0,0 +1,139 @@
+package structmerge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordHandler collects the attributes of the records it handles.
+type recordHandler struct {
+	level   slog.Level
+	records []map[string]string
+}
+
+func (h *recordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := map[string]string{"level": r.Level.String()}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	h.records = append(h.records, attrs)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+type LoggedAccount struct {
+	Name     string
+	Age      int
+	Tags     []string `merge:"append"`
+	Timeout  time.Duration
+	Password string `merge:"mask"`
+	Address  Address
+}
+
+func TestMergeLogger(t *testing.T) {
+	h := &recordHandler{level: slog.LevelDebug}
+	dst := LoggedAccount{Name: "alice", Age: 30, Tags: []string{"a"}, Password: "secret"}
+	src := LoggedAccount{Name: "bob", Tags: []string{"b"}, Timeout: time.Second, Password: "hunter2"}
+
+	cfg := Config{Option: ExcludeEmpty, Logger: slog.New(h), Exclude: []string{"Address"}}
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []map[string]string{
+		{"level": "DEBUG", "path": "Name", "action": "set", "option": "ExcludeEmpty", "old": "alice", "new": "bob"},
+		{"level": "DEBUG", "path": "Age", "action": "skip", "option": "ExcludeEmpty", "old": "30", "new": "0"},
+		{"level": "DEBUG", "path": "Tags", "action": "append", "option": "ExcludeEmpty"},
+		{"level": "DEBUG", "path": "Timeout", "action": "set", "option": "ExcludeEmpty", "old": "0s", "new": "1s"},
+		{"level": "DEBUG", "path": "Password", "action": "skip", "option": "ExcludeEmpty", "old": "[REDACTED]", "new": "[REDACTED]"},
+	}
+	if !reflect.DeepEqual(h.records, expected) {
+		t.Errorf("records =\n%v\nwant\n%v", h.records, expected)
+	}
+}
+
+func TestMergeLoggerLevel(t *testing.T) {
+	// Nothing is logged when debug records are disabled.
+	h := &recordHandler{level: slog.LevelInfo}
+	var dst LoggedAccount
+	if err := Merge(&dst, LoggedAccount{Name: "bob"}, Config{Logger: slog.New(h)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(h.records) != 0 {
+		t.Errorf("unexpected records %v", h.records)
+	}
+
+	// The dry-run value is logged without touching dst.
+	dst = LoggedAccount{}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if err := Merge(&dst, LoggedAccount{Name: "bob"}, Config{Logger: logger, DryRun: true, Include: []string{"Name"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Name != "" || !bytes.Contains(buf.Bytes(), []byte("path=Name action=set option=IncludeAll old=\"\" new=bob")) {
+		t.Errorf("unexpected log output %q", buf.String())
+	}
+}
+
+func TestMergeLoggerUnknownPaths(t *testing.T) {
+	h := &recordHandler{level: slog.LevelWarn}
+	cfg := Config{
+		Logger:  slog.New(h),
+		Include: []string{"Name", "Adress.City", "Tags.*"},
+		Exclude: []string{"Pasword", "Address.Street"},
+	}
+
+	// Paths are only checked on the first merge with a configuration.
+	for i := 0; i < 2; i++ {
+		var dst LoggedAccount
+		if err := Merge(&dst, LoggedAccount{Name: "bob"}, cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []map[string]string{
+		{"level": "WARN", "path": "Adress.City", "list": "Include", "type": "structmerge.LoggedAccount"},
+		{"level": "WARN", "path": "Pasword", "list": "Exclude", "type": "structmerge.LoggedAccount"},
+	}
+	if !reflect.DeepEqual(h.records, expected) {
+		t.Errorf("records =\n%v\nwant\n%v", h.records, expected)
+	}
+}
+
+func TestMergeLoggerUnknownPathsBounded(t *testing.T) {
+	h := &recordHandler{level: slog.LevelWarn}
+	logger := slog.New(h)
+
+	// Every merge names a different path; the record of checked paths
+	// must not keep them all.
+	for i := 0; i < maxPathWarnings+10; i++ {
+		var dst LoggedAccount
+		cfg := Config{Logger: logger, Exclude: []string{fmt.Sprintf("Missing%d", i)}}
+		if err := Merge(&dst, LoggedAccount{Name: "bob"}, cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(h.records) != maxPathWarnings+10 {
+		t.Errorf("got %d warnings, want %d", len(h.records), maxPathWarnings+10)
+	}
+
+	pathWarnings.Lock()
+	n := len(pathWarnings.seen)
+	pathWarnings.Unlock()
+	if n > maxPathWarnings {
+		t.Errorf("%d paths remembered, want at most %d", n, maxPathWarnings)
+	}
+}
@@ -0,0 +1,34 @@
+package structmerge
+
+import (
+	"reflect"
+	"sync"
+)
+
+// mergeFuncs maps a reflect.Type to its registered merge function.
+var mergeFuncs sync.Map
+
+// RegisterMergeFunc registers fn to merge struct fields of type t, for types
+// that cannot implement Merger, such as types from other packages. fn
+// receives the settable destination field and the source field, and is
+// responsible for assigning the result; it takes precedence over Merger and
+// the merge option, like a Merger does. Registering a nil fn removes the
+// function. It is safe for concurrent use.
+//
+// Errors returned by fn are wrapped in a *FieldError.
+func RegisterMergeFunc(t reflect.Type, fn func(dst, src reflect.Value) error) {
+	if fn == nil {
+		mergeFuncs.Delete(t)
+		return
+	}
+	mergeFuncs.Store(t, fn)
+}
+
+// mergeFunc returns the merge function registered for t, if any.
+func mergeFunc(t reflect.Type) (func(dst, src reflect.Value) error, bool) {
+	fn, ok := mergeFuncs.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return fn.(func(dst, src reflect.Value) error), true
+}
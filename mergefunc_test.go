@@ -0,0 +1,75 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegisterMergeFunc(t *testing.T) {
+	var calls []string
+	RegisterMergeFunc(reflect.TypeOf(UUID{}), func(dst, src reflect.Value) error {
+		calls = append(calls, "called")
+		// Keep the first non-zero ID.
+		if dst.IsZero() {
+			dst.Set(src)
+		}
+		return nil
+	})
+	t.Cleanup(func() { RegisterMergeFunc(reflect.TypeOf(UUID{}), nil) })
+
+	dst := Record{ID: UUID{1}, Name: "Alice"}
+	if err := Merge(&dst, Record{ID: UUID{2}, Name: "Bob"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (Record{ID: UUID{1}, Name: "Bob"}); dst != expected {
+		t.Errorf("Merge() = %+v, want %+v", dst, expected)
+	}
+
+	dst = Record{Name: "Alice"}
+	if err := Merge(&dst, Record{ID: UUID{2}}, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (Record{ID: UUID{2}, Name: "Alice"}); dst != expected {
+		t.Errorf("Merge() = %+v, want %+v", dst, expected)
+	}
+
+	if len(calls) != 2 {
+		t.Errorf("merge func called %d times, want 2", len(calls))
+	}
+}
+
+func TestRegisterMergeFuncError(t *testing.T) {
+	errBad := errors.New("bad id")
+	RegisterMergeFunc(reflect.TypeOf(UUID{}), func(dst, src reflect.Value) error {
+		return errBad
+	})
+	t.Cleanup(func() { RegisterMergeFunc(reflect.TypeOf(UUID{}), nil) })
+
+	var dst Record
+	err := Merge(&dst, Record{ID: UUID{2}})
+	var fieldErr *FieldError
+	if !errors.Is(err, errBad) || !errors.As(err, &fieldErr) || fieldErr.Path != "ID" {
+		t.Errorf("expected FieldError for ID wrapping errBad, got %v", err)
+	}
+}
+
+func TestRegisterMergeFuncDryRun(t *testing.T) {
+	RegisterMergeFunc(reflect.TypeOf(UUID{}), func(dst, src reflect.Value) error {
+		dst.Set(src)
+		return nil
+	})
+	t.Cleanup(func() { RegisterMergeFunc(reflect.TypeOf(UUID{}), nil) })
+
+	dst := Record{ID: UUID{1}}
+	result, err := MergeDryRun(&dst, Record{ID: UUID{2}}, Config{Include: []string{"ID"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.ID != (UUID{1}) {
+		t.Errorf("destination modified: %+v", dst)
+	}
+	if !reflect.DeepEqual(result.Changed, []string{"ID"}) {
+		t.Errorf("Changed = %v, want [ID]", result.Changed)
+	}
+}
@@ -0,0 +1,94 @@
+package structmerge
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Patch is a serializable set of changes, mapping field paths (in the same
+// dot notation as Config.Include) to their new values.
+//
+// A Patch round-trips through JSON. After decoding, values are kept as
+// json.RawMessage and converted to the field types when the patch is applied.
+type Patch struct {
+	Changes map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p Patch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Changes)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p.Changes = make(map[string]interface{}, len(raw))
+	for path, value := range raw {
+		p.Changes[path] = value
+	}
+	return nil
+}
+
+// CreatePatch returns a Patch holding the fields that differ between from
+// and to, with the values taken from to.
+func CreatePatch(from, to interface{}) (*Patch, error) {
+	paths, err := Diff(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	toValue, _ := structValue(to)
+	p := &Patch{Changes: make(map[string]interface{}, len(paths))}
+	for _, path := range paths {
+		field, err := lookupPath(toValue, path, false)
+		if err != nil {
+			return nil, err
+		}
+		p.Changes[path] = field.Interface()
+	}
+	return p, nil
+}
+
+// ApplyPatch merges the changes in p into dst, which must be a pointer to a
+// struct. Only the fields listed in the patch are merged: the Include list of
+// cfg is replaced by the patch paths, while the other settings still apply.
+func ApplyPatch(dst interface{}, p *Patch, cfg ...Config) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	// Build the source from a deep copy of dst so that writing the patched
+	// values never reaches memory shared with dst.
+	src := reflect.New(dstValue.Elem().Type())
+	src.Elem().Set(dstValue.Elem())
+	copyCfg := Config{Option: IncludeAll, DeepCopyPointers: true}
+	if err := mergeValues(newMergeState(context.Background()), src, dstValue.Elem(), copyCfg, ""); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(p.Changes))
+	for path, value := range p.Changes {
+		field, err := lookupPath(src.Elem(), path, true)
+		if err != nil {
+			return err
+		}
+		if err := setValue(field, value); err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		paths = append(paths, path)
+	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	config := configOf(cfg)
+	config.Include = paths
+	return mergeValues(newMergeState(context.Background()), dstValue, src.Elem(), config, "")
+}
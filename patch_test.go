@@ -0,0 +1,88 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPatchRoundTrip(t *testing.T) {
+	from := Event{
+		Name: "Meetup",
+		Location: Location{
+			Address: Address{Street: "1 Main St", City: "Kampala"},
+		},
+		Start: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	to := from
+	to.Name = "Conference"
+	to.Location.Address.City = "Entebbe"
+	to.Location.Geo = Geo{Lat: 0.05, Lng: 32.46}
+	to.Host = &Person{Name: "Alice", Age: 30, Address: &Address{Country: "Uganda"}}
+	to.Tags = []string{"go", "community"}
+	to.Start = time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+
+	p, err := CreatePatch(from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var decoded Patch
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if len(decoded.Changes) != len(p.Changes) {
+		t.Fatalf("expected %d changes, got %d", len(p.Changes), len(decoded.Changes))
+	}
+
+	// Apply both the in-memory and the decoded patch.
+	for name, patch := range map[string]*Patch{"memory": p, "json": &decoded} {
+		t.Run(name, func(t *testing.T) {
+			dst := from
+			if err := ApplyPatch(&dst, patch); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, to) {
+				t.Errorf("expected %#v, got %#v", to, dst)
+			}
+		})
+	}
+}
+
+func TestApplyPatchNestedPointer(t *testing.T) {
+	host := &Person{Name: "Alice", Address: &Address{City: "Kampala"}}
+	dst := Event{Host: host}
+
+	p := &Patch{Changes: map[string]interface{}{"Host.Address.City": "Jinja"}}
+	if err := ApplyPatch(&dst, p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dst.Host.Address.City != "Jinja" || dst.Host.Name != "Alice" {
+		t.Errorf("unexpected host: %#v", dst.Host)
+	}
+	if host.Address.City != "Kampala" {
+		t.Error("ApplyPatch modified memory shared with the original value")
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	p := &Patch{Changes: map[string]interface{}{"Missing": 1}}
+
+	var dst Event
+	if err := ApplyPatch(&dst, p); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+	if err := ApplyPatch(dst, p); err != ErrInvalidDestination {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// lookupPath returns the field of the struct v at the dot-separated path.
+// Pointers to structs along the path are dereferenced. If alloc is true, nil
+// pointers are allocated on the way, which requires v to be addressable;
+// otherwise a nil pointer yields an invalid reflect.Value and no error.
+func lookupPath(v reflect.Value, path string, alloc bool) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, nil
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, &FieldError{Path: path, Err: ErrInvalidPath}
+		}
+
+		field, ok := v.Type().FieldByName(name)
+		if !ok || !field.IsExported() {
+			return reflect.Value{}, &FieldError{Path: path, Err: ErrInvalidPath}
+		}
+		v = v.FieldByIndex(field.Index)
+	}
+	return v, nil
+}
+
+// checkPath returns a *FieldError wrapping ErrInvalidPath if path does not
+// name a field of the struct type t. Pointers are dereferenced, and the
+// segment following a map or slice field is taken as a map or element key.
+// Only the segments before the first wildcard of a pattern are checked.
+func checkPath(t reflect.Type, path string) error {
+	segments := strings.Split(path, ".")
+	for i := 0; i < len(segments); i++ {
+		if isGlob(segments[i]) {
+			return nil
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := t.FieldByName(segments[i])
+			if !ok || !field.IsExported() {
+				return &FieldError{Path: path, Err: ErrInvalidPath}
+			}
+			t = field.Type
+		case reflect.Map, reflect.Slice:
+			t = t.Elem() // segments[i] is a map or element key
+		default:
+			return &FieldError{Path: path, Err: ErrInvalidPath}
+		}
+	}
+	return nil
+}
+
+// setValue assigns value to the settable field dst, converting it to the
+// field's type where needed. Numbers out of the range of a numeric field,
+// and floats with a fractional part for an integer field, fail with
+// ErrNumericOverflow. Values that are neither assignable nor numeric are
+// converted by round-tripping them through JSON, which also covers
+// json.RawMessage values decoded from a Patch.
+func setValue(dst reflect.Value, value interface{}) error {
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(dst.Type()) {
+		dst.Set(v)
+		return nil
+	}
+
+	if isNumber(v.Kind()) && isNumber(dst.Kind()) {
+		if isFloat(v.Kind()) && !isFloat(dst.Kind()) {
+			if f := v.Float(); !math.IsNaN(f) && f != math.Trunc(f) {
+				return &MergeError{Code: ErrCodeNumericOverflow, Cause: fmt.Errorf("%v is not an integer", f)}
+			}
+		}
+		converted, ok := coerceNumber(v, dst.Type())
+		if !ok {
+			return ErrNumericOverflow
+		}
+		dst.Set(converted)
+		return nil
+	}
+
+	data, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, dst.Addr().Interface())
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// resolveConfig prepares cfg for merging into the struct type t.
+func resolveConfig(t reflect.Type, cfg Config) Config {
+	if cfg.UseJSONTags || cfg.FieldRenamer != nil {
+		cfg.Include = resolvePaths(t, cfg.Include, cfg)
+		cfg.Exclude = resolvePaths(t, cfg.Exclude, cfg)
+	}
+	if len(cfg.Groups) > 0 {
+		// Fields in the selected groups are merged as if they were listed
+		// in Include.
+		include := append([]string(nil), cfg.Include...)
+		cfg.Include = append(include, groupPaths(t, cfg.Groups, "", make(map[reflect.Type]bool))...)
+	}
+	return cfg
+}
+
+// resolvePaths rewrites paths that name fields of the struct type t by their
+// `json` tag names, if cfg.UseJSONTags is set, or by the names returned by
+// cfg.FieldRenamer into paths that use the Go field names. Segments that
+// cannot be resolved, such as map keys, are kept as they are.
+func resolvePaths(t reflect.Type, paths []string, cfg Config) []string {
+	if len(paths) == 0 {
+		return paths
+	}
+
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		resolved[i] = resolvePath(t, path, cfg.UseJSONTags, cfg.FieldRenamer)
+	}
+	return resolved
+}
+
+// resolvePath rewrites path as resolvePaths does. Segments are matched by
+// `json` tag name if useJSON is set, and by the name returned by rename if
+// it is not nil.
+func resolvePath(t reflect.Type, path string, useJSON bool, rename func(string) string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+
+		field, ok := fieldByName(t, segment, useJSON, rename)
+		if !ok {
+			break
+		}
+		segments[i] = field.Name
+		t = field.Type
+	}
+	return strings.Join(segments, ".")
+}
+
+// fieldByName returns the exported field of the struct type t with the given
+// Go name or, failing that, `json` tag name if useJSON is set or name given
+// by rename if it is not nil.
+func fieldByName(t reflect.Type, name string, useJSON bool, rename func(string) string) (reflect.StructField, bool) {
+	if field, ok := t.FieldByName(name); ok && field.IsExported() {
+		return field, true
+	}
+
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		if (useJSON && jsonName(field) == name) || (rename != nil && rename(field.Name) == name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonName returns the name given to field by its `json` tag, if any.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,106 @@
+package structmerge
+
+import (
+	"context"
+	"reflect"
+)
+
+// Pipeline merges a sequence of sources into the same destination, each
+// with its own configuration.
+//
+//	err := structmerge.NewPipeline().
+//		Add(defaults, structmerge.Config{Option: structmerge.ExcludeEmpty}).
+//		Add(overrides, structmerge.Config{Include: []string{"Name"}}).
+//		Execute(&dst)
+type Pipeline struct {
+	stages          []pipelineStage
+	typ             reflect.Type
+	errs            ErrorList
+	continueOnError bool
+}
+
+type pipelineStage struct {
+	src reflect.Value
+	cfg Config
+}
+
+// NewPipeline returns an empty Pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Add appends a stage merging src with cfg. src must be a struct of the same
+// type as the sources of the earlier stages, and the paths in cfg must name
+// its fields. A stage that fails these checks is not added; its error is
+// reported by Err and Execute.
+func (p *Pipeline) Add(src interface{}, cfg Config) *Pipeline {
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Struct {
+		p.errs = append(p.errs, ErrInvalidSource)
+		return p
+	}
+
+	if p.typ == nil {
+		p.typ = v.Type()
+	} else if v.Type() != p.typ {
+		p.errs = append(p.errs, ErrTypeMismatch)
+		return p
+	}
+
+	if err := ValidateConfig(cfg, p.typ); err != nil {
+		p.errs = append(p.errs, err)
+		return p
+	}
+
+	p.stages = append(p.stages, pipelineStage{src: v, cfg: cfg})
+	return p
+}
+
+// ContinueOnError makes Execute run every stage even if some fail, and
+// report their errors together in an ErrorList. By default Execute stops
+// at the first failing stage.
+func (p *Pipeline) ContinueOnError() *Pipeline {
+	p.continueOnError = true
+	return p
+}
+
+// Err returns the errors of the stages rejected by Add, if any.
+func (p *Pipeline) Err() error {
+	if len(p.errs) > 0 {
+		return p.errs
+	}
+	return nil
+}
+
+// Execute runs the stages in the order they were added, merging each source
+// into dst. If a stage was rejected by Add, dst is left untouched and the
+// error is returned.
+func (p *Pipeline) Execute(dst interface{}) error {
+	if err := p.Err(); err != nil {
+		return err
+	}
+
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+	if p.typ != nil && dstValue.Elem().Type() != p.typ {
+		return ErrTypeMismatch
+	}
+
+	var errs ErrorList
+	state := newMergeState(context.Background())
+	for _, stage := range p.stages {
+		if err := mergeValues(state, dstValue, stage.src, stage.cfg, ""); err != nil {
+			if !p.continueOnError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
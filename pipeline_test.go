@@ -0,0 +1,110 @@
+package structmerge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	defaults := TestStruct{Name: "default", Age: 18, Address: Address{City: "Kampala", Country: "Uganda"}}
+	update := TestStruct{Name: "Alice", Address: Address{City: "Gulu"}}
+	override := TestStruct{Count: 0, Active: false}
+
+	dst := TestStruct{Active: true, Count: 7}
+	err := NewPipeline().
+		Add(defaults, Config{Option: ExcludeEmpty}).
+		Add(update, Config{Option: ExcludeEmpty, Exclude: []string{"Address.City"}}).
+		Add(override, Config{Include: []string{"Count"}}).
+		Execute(&dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{
+		Name:    "Alice",
+		Age:     18,
+		Address: Address{City: "Kampala", Country: "Uganda"},
+		Active:  true,
+	}
+	if dst != expected {
+		t.Errorf("expected %+v, got %+v", expected, dst)
+	}
+}
+
+func TestPipelineAddErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline *Pipeline
+		expected error
+	}{
+		{
+			name:     "Invalid source",
+			pipeline: NewPipeline().Add(&TestStruct{}, Config{}),
+			expected: ErrInvalidSource,
+		},
+		{
+			name:     "Type mismatch",
+			pipeline: NewPipeline().Add(TestStruct{}, Config{}).Add(Person{}, Config{}),
+			expected: ErrTypeMismatch,
+		},
+		{
+			name:     "Invalid path",
+			pipeline: NewPipeline().Add(TestStruct{}, Config{Include: []string{"Nmae"}}),
+			expected: ErrInvalidPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.pipeline.Err(); !errors.Is(err, tt.expected) {
+				t.Fatalf("Err(): expected %v, got %v", tt.expected, err)
+			}
+
+			dst := TestStruct{Name: "unchanged"}
+			if err := tt.pipeline.Execute(&dst); !errors.Is(err, tt.expected) {
+				t.Fatalf("Execute(): expected %v, got %v", tt.expected, err)
+			}
+			if dst.Name != "unchanged" {
+				t.Errorf("expected dst to be left untouched, got %+v", dst)
+			}
+		})
+	}
+}
+
+func TestPipelineExecuteErrors(t *testing.T) {
+	p := NewPipeline().Add(TestStruct{Name: "Alice"}, Config{})
+
+	var person Person
+	if err := p.Execute(&person); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+	if err := p.Execute(TestStruct{}); !errors.Is(err, ErrInvalidDestination) {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
+
+func TestPipelineContinueOnError(t *testing.T) {
+	tooDeep := Config{MaxDepth: 1, MaxDepthBehavior: MaxDepthError}
+
+	p := NewPipeline().
+		Add(TestStruct{Name: "Alice", Address: Address{City: "Gulu"}}, tooDeep).
+		Add(TestStruct{Age: 30}, Config{Option: ExcludeEmpty})
+
+	var dst TestStruct
+	if err := p.Execute(&dst); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("expected ErrMaxDepthExceeded, got %v", err)
+	}
+	if dst.Age != 0 {
+		t.Errorf("expected the pipeline to stop at the first error, got %+v", dst)
+	}
+
+	dst = TestStruct{}
+	err := p.ContinueOnError().Execute(&dst)
+	var errs ErrorList
+	if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("expected an ErrorList with ErrMaxDepthExceeded, got %v", err)
+	}
+	if dst.Age != 30 {
+		t.Errorf("expected the later stage to run, got %+v", dst)
+	}
+}
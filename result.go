@@ -0,0 +1,51 @@
+package structmerge
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// MergeResult describes a completed merge.
+type MergeResult struct {
+	// Option is the merge option the merge ran with.
+	Option MergeOption
+
+	// Changed lists the paths of the fields that were set to a new value,
+	// in merge order.
+	Changed []string
+
+	// Skipped lists the paths of the fields left untouched because of the
+	// merge option or a `merge:"omitempty"` tag.
+	Skipped []string
+
+	// Duration is the time the merge took.
+	Duration time.Duration
+
+	// FieldCount is the number of fields considered for merging, not
+	// counting fields left out by Include or Exclude.
+	FieldCount int
+}
+
+// MergeWithResult is like Merge but also reports what the merge did.
+// cfg.OnFieldSet, if set, is still called for every changed field.
+// On error the result describes the fields merged before the failure.
+func MergeWithResult(dst, src interface{}, cfg ...Config) (*MergeResult, error) {
+	start := time.Now()
+	config := configOf(cfg)
+
+	result := &MergeResult{Option: config.Option}
+	onFieldSet := config.OnFieldSet
+	config.OnFieldSet = func(path string, oldVal, newVal reflect.Value) {
+		result.Changed = append(result.Changed, path)
+		if onFieldSet != nil {
+			onFieldSet(path, oldVal, newVal)
+		}
+	}
+
+	state := newMergeState(context.Background())
+	state.result = result
+	err := mergeValues(state, reflect.ValueOf(dst), reflect.ValueOf(src), config, "")
+	result.Duration = time.Since(start)
+	return result, err
+}
@@ -0,0 +1,67 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeWithResult(t *testing.T) {
+	dst := TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Kampala"}}
+	src := TestStruct{Name: "Bob", Age: 30, Address: Address{Street: "Main St"}}
+
+	var called []string
+	cfg := Config{
+		Option: ExcludeEmpty,
+		OnFieldSet: func(path string, oldVal, newVal reflect.Value) {
+			called = append(called, path)
+		},
+	}
+
+	result, err := MergeWithResult(&dst, src, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Name: "Bob", Age: 30, Address: Address{Street: "Main St", City: "Kampala"}}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %+v, want %+v", dst, expected)
+	}
+
+	if want := []string{"Name", "Address.Street"}; !reflect.DeepEqual(result.Changed, want) {
+		t.Errorf("Changed = %v, want %v", result.Changed, want)
+	}
+	if want := []string{"Address.City", "Address.Country", "Active", "Count"}; !reflect.DeepEqual(result.Skipped, want) {
+		t.Errorf("Skipped = %v, want %v", result.Skipped, want)
+	}
+	if result.Option != ExcludeEmpty || result.Option.String() != "ExcludeEmpty" {
+		t.Errorf("Option = %v, want ExcludeEmpty", result.Option)
+	}
+	if result.FieldCount != 7 {
+		t.Errorf("FieldCount = %d, want 7", result.FieldCount)
+	}
+	if result.Duration < 0 {
+		t.Errorf("Duration = %v, want >= 0", result.Duration)
+	}
+	if !reflect.DeepEqual(called, result.Changed) {
+		t.Errorf("OnFieldSet called for %v, want %v", called, result.Changed)
+	}
+}
+
+func TestMergeWithResultExclude(t *testing.T) {
+	var dst TestStruct
+	result, err := MergeWithResult(&dst, TestStruct{Name: "Bob", Age: 3}, Config{Exclude: []string{"Age", "Address"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.FieldCount != 3 || len(result.Skipped) != 0 {
+		t.Errorf("got FieldCount %d and Skipped %v", result.FieldCount, result.Skipped)
+	}
+}
+
+func TestMergeWithResultError(t *testing.T) {
+	var dst TestStruct
+	if _, err := MergeWithResult(&dst, Address{}); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package structmerge
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Snapshot records the exported fields of a struct so that they can be
+// restored later, for example to undo a merge. It is stored as the
+// flattened map returned by ToMap rather than as a struct value, so it
+// does not depend on the struct type: it can be persisted as JSON and
+// restored into a later version of the struct that has gained fields.
+//
+//	snap, err := structmerge.NewSnapshot(cfg)
+//	_ = structmerge.Merge(&cfg, overrides)
+//	// ...
+//	err = snap.Restore(&cfg)
+type Snapshot struct {
+	values map[string]interface{}
+}
+
+// NewSnapshot returns a Snapshot of src, a struct or a non-nil pointer to a
+// struct. Pointer fields are copied first, so later changes to the values
+// they point to do not affect the snapshot.
+//
+// It is not named Snapshot because Go does not allow a function to share
+// its name with the Snapshot type.
+func NewSnapshot(src interface{}) (Snapshot, error) {
+	cp, err := DeepCopy(src)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	values, err := ToMap(cp)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{values: values}, nil
+}
+
+// Restore sets the fields of dst, a pointer to a struct, to their values in
+// the snapshot, as FromMap does. Fields the snapshot does not record, such
+// as fields added to the struct since, are left untouched. A recorded field
+// that dst does not have fails with ErrInvalidPath and leaves dst unchanged.
+func (s Snapshot) Restore(dst interface{}) error {
+	return FromMap(dst, s.values)
+}
+
+// MarshalJSON encodes the snapshot as a JSON object keyed by field path.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	if s.values == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(s.values)
+}
+
+// UnmarshalJSON decodes a snapshot encoded by MarshalJSON. Numbers are kept
+// as json.Number to avoid precision loss.
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var values map[string]interface{}
+	if err := dec.Decode(&values); err != nil {
+		return err
+	}
+	s.values = values
+	return nil
+}
@@ -0,0 +1,113 @@
+package structmerge
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Wallet struct {
+	ID      int64
+	Name    string
+	Limit   *int
+	Tags    []string
+	Address *Address
+	Opened  time.Time
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	limit := 100
+	wallet := Wallet{
+		ID:      1 << 60,
+		Name:    "Alice",
+		Limit:   &limit,
+		Address: &Address{City: "Kampala"},
+		Opened:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	want := Clone(wallet)
+
+	snap, err := NewSnapshot(&wallet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	more := 500
+	if err := Merge(&wallet, Wallet{Name: "Bob", Limit: &more, Tags: []string{"vip"}, Address: &Address{City: "Gulu"}}, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limit = 0 // the snapshot holds its own copy
+
+	if err := snap.Restore(&wallet); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(wallet, want) {
+		t.Errorf("Restore() = %+v, want %+v", wallet, want)
+	}
+}
+
+func TestSnapshotIndependentSlices(t *testing.T) {
+	wallet := Wallet{Name: "Alice", Tags: []string{"new"}}
+	snap, err := NewSnapshot(&wallet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Editing the slice in place leaves the snapshot as it was.
+	wallet.Tags[0] = "edited"
+	if err := snap.Restore(&wallet); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(wallet.Tags, []string{"new"}) {
+		t.Errorf("Tags = %v, want [new]", wallet.Tags)
+	}
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	limit := 100
+	wallet := Wallet{ID: 1<<60 + 1, Name: "Alice", Limit: &limit, Tags: []string{"a"}, Opened: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+
+	snap, err := NewSnapshot(wallet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded Snapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The snapshot restores into a struct that has gained a field.
+	type WalletV2 struct {
+		Wallet
+		Currency string
+	}
+	got := WalletV2{Wallet: Wallet{Name: "Bob", Address: &Address{City: "Gulu"}}, Currency: "UGX"}
+	if err := decoded.Restore(&got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := WalletV2{Wallet: wallet, Currency: "UGX"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Restore() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	if _, err := NewSnapshot(42); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+
+	snap, err := NewSnapshot(Wallet{Name: "Alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dst := Person{Name: "Bob"}
+	if err := snap.Restore(&dst); !errors.Is(err, ErrInvalidPath) || dst.Name != "Bob" {
+		t.Errorf("expected ErrInvalidPath and an untouched dst, got %v, %+v", err, dst)
+	}
+}
@@ -1,27 +1,153 @@
 package structmerge
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"reflect"
 	"strings"
 	"time"
 )
 
 var (
-	ErrInvalidDestination = newMergeError("destination must be a pointer to a struct")
-	ErrInvalidSource      = newMergeError("source must be a struct")
-	ErrTypeMismatch       = newMergeError("source and destination types do not match")
+	ErrInvalidDestination   = &MergeError{Code: ErrCodeInvalidDest}
+	ErrInvalidSource        = &MergeError{Code: ErrCodeInvalidSrc}
+	ErrTypeMismatch         = &MergeError{Code: ErrCodeTypeMismatch}
+	ErrInvalidPath          = &MergeError{Code: ErrCodeInvalidPath}
+	ErrCyclicReference      = &MergeError{Code: ErrCodeCyclicReference}
+	ErrConflictingPaths     = &MergeError{Code: ErrCodeConflictingPaths}
+	ErrUnknownKey           = &MergeError{Code: ErrCodeUnknownKey}
+	ErrMaxDepthExceeded     = &MergeError{Code: ErrCodeMaxDepthExceeded}
+	ErrUnsupportedOperation = &MergeError{Code: ErrCodeUnsupportedOperation}
+	ErrDuplicateKey         = &MergeError{Code: ErrCodeDuplicateKey}
+	ErrImmutableField       = &MergeError{Code: ErrCodeImmutableField}
+	ErrNumericOverflow      = &MergeError{Code: ErrCodeNumericOverflow}
 )
 
+// ErrorCode identifies the kind of a MergeError.
+type ErrorCode int
+
+const (
+	ErrCodeInvalidDest ErrorCode = iota + 1
+	ErrCodeInvalidSrc
+	ErrCodeTypeMismatch
+	ErrCodeInvalidPath
+	ErrCodeCyclicReference
+	ErrCodeConflictingPaths
+	ErrCodeUnknownKey
+	ErrCodeMaxDepthExceeded
+	ErrCodeUnsupportedOperation
+	ErrCodeDuplicateKey
+	ErrCodeImmutableField
+	ErrCodeNumericOverflow
+)
+
+var errorMessages = map[ErrorCode]string{
+	ErrCodeInvalidDest:          "destination must be a pointer to a struct",
+	ErrCodeInvalidSrc:           "source must be a struct",
+	ErrCodeTypeMismatch:         "source and destination types do not match",
+	ErrCodeInvalidPath:          "field path does not exist",
+	ErrCodeCyclicReference:      "cyclic reference detected",
+	ErrCodeConflictingPaths:     "include and exclude paths conflict",
+	ErrCodeUnknownKey:           "map key does not match any field",
+	ErrCodeMaxDepthExceeded:     "maximum merge depth exceeded",
+	ErrCodeUnsupportedOperation: "unsupported patch operation",
+	ErrCodeDuplicateKey:         "duplicate key",
+	ErrCodeImmutableField:       "field is immutable",
+	ErrCodeNumericOverflow:      "number out of range of the destination type",
+}
+
+// String returns the message describing the error code.
+func (c ErrorCode) String() string {
+	if msg, ok := errorMessages[c]; ok {
+		return msg
+	}
+	return fmt.Sprintf("merge error %d", int(c))
+}
+
+// MergeError is an error raised by the package. The exported Err variables
+// are MergeErrors without a path or cause, and errors.Is matches any
+// MergeError against them by Code.
+//
+// Errors for nested fields are wrapped in a *FieldError; errors.As with a
+// *MergeError target sees through it and fills in the field path.
 type MergeError struct {
-	message string
+	Code  ErrorCode
+	Path  string // dot-separated field path, if known
+	Cause error  // underlying error, if any
 }
 
 func (e *MergeError) Error() string {
-	return e.message
+	msg := e.Code.String()
+	if e.Path != "" {
+		msg = e.Path + ": " + msg
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Is reports whether target is a *MergeError with the same Code.
+func (e *MergeError) Is(target error) bool {
+	t, ok := target.(*MergeError)
+	return ok && t.Code == e.Code
+}
+
+func (e *MergeError) Unwrap() error {
+	return e.Cause
+}
+
+// FieldError records the path of the field where a merge failed.
+// It wraps the underlying error, so errors.Is and errors.As see through it.
+type FieldError struct {
+	Path string // dot-separated field path, as in Config.Include
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// As lets errors.As extract a *MergeError from e with Path set to the path
+// of the field. The innermost FieldError, which has the most precise path,
+// provides it.
+func (e *FieldError) As(target interface{}) bool {
+	t, ok := target.(**MergeError)
+	if !ok {
+		return false
+	}
+
+	var inner *FieldError
+	if errors.As(e.Err, &inner) {
+		return false
+	}
+
+	var mergeErr *MergeError
+	if !errors.As(e.Err, &mergeErr) {
+		return false
+	}
+
+	*t = &MergeError{Code: mergeErr.Code, Path: e.Path, Cause: mergeErr.Cause}
+	if mergeErr.Path != "" {
+		(*t).Path = mergeErr.Path
+	}
+	return true
 }
 
-func newMergeError(message string) *MergeError {
-	return &MergeError{message: message}
+// wrapError wraps a non-nil err in a FieldError for the struct at prefix.
+// Errors for the top-level struct are returned unchanged.
+func wrapError(prefix string, err error) error {
+	if err == nil || prefix == "" {
+		return err
+	}
+	return &FieldError{Path: strings.TrimSuffix(prefix, "."), Err: err}
 }
 
 // Merger interface allows custom structs that don't export fields to be merged.
@@ -32,9 +158,36 @@ type Merger interface {
 	Merge(src reflect.Value) error
 }
 
+// MergerWithContext is a Merger that receives the context of the merge, for
+// custom merges doing I/O that should stop when it is cancelled. It takes
+// priority over Merger, and MergeCtx is not called once the context is done.
+type MergerWithContext interface {
+	MergeCtx(ctx context.Context, src reflect.Value) error
+}
+
+// Masker marks types whose values must never be overwritten by a merge, such
+// as password hashes or private keys. Fields of a type implementing Masker,
+// like fields tagged `merge:"mask"`, are left untouched whatever the Config,
+// even when Include names them.
+type Masker interface {
+	Mask()
+}
+
 // MergeOption defined the behavior for merging fields.
 type MergeOption int
 
+// SourcePriority and DestinationPriority name the two most common options
+// after the side that wins a conflict.
+const (
+	// SourcePriority lets source values always win. It is the same option
+	// as IncludeAll.
+	SourcePriority MergeOption = IncludeAll
+
+	// DestinationPriority lets destination values win once set. It is the
+	// same option as KeepFirst.
+	DestinationPriority MergeOption = KeepFirst
+)
+
 const (
 	// IncludeAll includes all fields in the merge.
 	IncludeAll MergeOption = iota
@@ -44,6 +197,145 @@ const (
 
 	// OverwriteEmpty overwrites empty fields in destination
 	OverwriteEmpty
+
+	// OverwriteNonEmpty overwrites only the fields that are already
+	// non-empty in destination, i.e. it updates existing values only.
+	OverwriteNonEmpty
+
+	// KeepFirst never overwrites a destination field once it has been set:
+	// source values are only copied into unset fields. Unlike OverwriteEmpty,
+	// a non-nil but empty slice or map counts as set. A false bool cannot be
+	// told apart from an unset one and is therefore always treated as unset.
+	KeepFirst
+
+	// FillEmpty copies non-empty source values into empty destination
+	// fields only, as when applying defaults. Unlike OverwriteEmpty it never
+	// clears a field with an empty source value, and a false bool in the
+	// destination counts as set unless Config.TreatBoolFalseAsZero is true.
+	FillEmpty
+
+	// ExcludeZeroInDst copies non-empty source values into empty destination
+	// fields only, filling the gaps of the destination. Unlike FillEmpty, a
+	// false bool in the destination counts as empty.
+	ExcludeZeroInDst
+
+	// NilToEmpty includes all fields like IncludeAll, but merges pointers
+	// to structs field by field instead of sharing the source pointer: a
+	// nil destination pointer is first set to a new zero value, so the
+	// destination never points into the source. A nil source pointer sets
+	// the destination pointer to nil.
+	NilToEmpty
+
+	// AppendSlices includes all fields like IncludeAll, but appends source
+	// elements to slice fields instead of replacing them, as if every slice
+	// field had the `merge:"append"` tag. Fields tagged `merge:"replace"`
+	// are still replaced, and json.RawMessage values are always replaced.
+	AppendSlices
+
+	// SymmetricDifference keeps the fields that are non-empty in exactly one
+	// of the source and the destination: a value only the source has is
+	// copied, a value only the destination has is kept, and fields that are
+	// set or empty on both sides are left untouched. The resulting struct is
+	// the same as with ExcludeZeroInDst; MergeWithResult reports the copied
+	// fields as Changed and the fields set on both sides among Skipped.
+	SymmetricDifference
+
+	// MaxIntWins keeps the larger of the two values of integer, unsigned
+	// and floating-point fields, as for counters merged CRDT-style. Other
+	// fields are merged as with IncludeAll.
+	MaxIntWins
+
+	// MinIntWins keeps the smaller of the two values of integer, unsigned
+	// and floating-point fields. Other fields are merged as with IncludeAll.
+	MinIntWins
+
+	// IntersectionOnly updates only the fields that are non-empty in both the
+	// source and the destination, so that a source cannot populate a field
+	// the destination never had. Fields kept by KeepFalseBools and the like
+	// count as non-empty.
+	IntersectionOnly
+)
+
+var mergeOptionNames = [...]string{
+	IncludeAll:          "IncludeAll",
+	ExcludeEmpty:        "ExcludeEmpty",
+	OverwriteEmpty:      "OverwriteEmpty",
+	OverwriteNonEmpty:   "OverwriteNonEmpty",
+	KeepFirst:           "KeepFirst",
+	FillEmpty:           "FillEmpty",
+	ExcludeZeroInDst:    "ExcludeZeroInDst",
+	NilToEmpty:          "NilToEmpty",
+	AppendSlices:        "AppendSlices",
+	SymmetricDifference: "SymmetricDifference",
+	MaxIntWins:          "MaxIntWins",
+	MinIntWins:          "MinIntWins",
+	IntersectionOnly:    "IntersectionOnly",
+}
+
+// String returns the name of the option, such as "IncludeAll". Aliases
+// report the name of the option they stand for.
+func (o MergeOption) String() string {
+	if o >= 0 && int(o) < len(mergeOptionNames) {
+		return mergeOptionNames[o]
+	}
+	return fmt.Sprintf("MergeOption(%d)", int(o))
+}
+
+// MapMergeStrategy defines how map fields are merged.
+type MapMergeStrategy int
+
+const (
+	// MapReplace replaces the destination map with the source map.
+	MapReplace MapMergeStrategy = iota
+
+	// MapMergeKeys adds the source keys that are missing from the
+	// destination map, leaving existing keys untouched.
+	MapMergeKeys
+
+	// MapMergeDeep adds missing keys like MapMergeKeys and, for keys present
+	// in both maps, merges struct values recursively. Other values present
+	// in both maps are replaced by the source value.
+	MapMergeDeep
+)
+
+// InterfaceMergeStrategy defines how interface-typed fields are merged.
+type InterfaceMergeStrategy int
+
+const (
+	// InterfaceReplace replaces the destination interface value with the
+	// source value.
+	InterfaceReplace InterfaceMergeStrategy = iota
+
+	// InterfaceMergeDeep merges the concrete values held by the destination
+	// and source when both are structs, or pointers to structs, of the same
+	// type. Other values are replaced as with InterfaceReplace.
+	InterfaceMergeDeep
+)
+
+// ByteSliceMergeStrategy defines how []byte fields are merged.
+type ByteSliceMergeStrategy int
+
+const (
+	// ByteSliceReplace replaces the destination bytes with the source bytes.
+	ByteSliceReplace ByteSliceMergeStrategy = iota
+
+	// ByteSliceAppend appends the source bytes to the destination bytes.
+	// json.RawMessage fields are still replaced, since concatenated JSON
+	// documents are not valid JSON.
+	ByteSliceAppend
+)
+
+// MaxDepthBehavior defines what happens to nested structs below
+// Config.MaxDepth.
+type MaxDepthBehavior int
+
+const (
+	// MaxDepthReplace treats nested structs below the maximum depth as
+	// opaque values, assigning them as a whole like any other field.
+	MaxDepthReplace MaxDepthBehavior = iota
+
+	// MaxDepthError makes the merge fail with ErrMaxDepthExceeded.
+	MaxDepthError
 )
 
 // Config holds configuration for the merge operation.
@@ -51,19 +343,313 @@ type Config struct {
 	Option  MergeOption
 	Include []string // Fields to include in the destination
 	Exclude []string // Fields to exclude from destination struct
+
+	// Groups selects the fields tagged with one of the groups, as in
+	// `merge:"group=pii"`, in addition to the fields listed in Include.
+	// A field may belong to several groups: `merge:"group=pii,audit"`.
+	// Tagging a nested struct selects all of its fields. Fields of structs
+	// reached through pointer fields are selected too, unless the pointer
+	// leads back to a struct type already being searched. IncludeFunc
+	// replaces Groups as it replaces Include.
+	Groups []string
+
+	// IncludeFunc, if set, replaces Include: a field is merged if
+	// IncludeFunc returns true for its path or the path of a struct it is
+	// nested in. Structs merged field by field, and the structs pointer
+	// fields point to, are always descended into, so IncludeFunc may select
+	// some of their fields only. Exclude and
+	// ExcludeFunc still take precedence. ValidateConfig rejects a Config
+	// setting both Include and IncludeFunc.
+	IncludeFunc func(path string) bool
+
+	// ExcludeFunc, if set, is called with the path of every field selected
+	// by Include (or IncludeFunc) and Exclude, nested structs included, and
+	// returns true to exclude the field (and the fields nested in it) as
+	// well.
+	ExcludeFunc func(path string) bool
+
+	// Transformers maps field paths (in the same notation as Include) to
+	// functions computing the value to assign from the destination and
+	// source values. They run just before a field is set.
+	Transformers map[string]func(dst, src reflect.Value) reflect.Value
+
+	// Transform maps a reflect.Kind to a function transforming the source
+	// value of every field of that kind, for example to trim strings or
+	// clamp integers. It runs before the merge option and tag options are
+	// applied, so the transformed value is the one checked for emptiness.
+	// Fields with an entry in Transformers are not transformed by kind.
+	Transform map[reflect.Kind]func(reflect.Value) reflect.Value
+
+	// ConflictResolver, if set, is called for fields that are not empty in
+	// both the destination and the source and returns the value to assign.
+	// Returning an invalid or zero reflect.Value leaves the field untouched.
+	// It is not called for fields that have a Transformer.
+	ConflictResolver func(path string, dst, src reflect.Value) reflect.Value
+
+	// FieldPredicate, if set, is called for every field selected by
+	// Include and Exclude, nested structs included, and returns false to
+	// leave the field (and the fields nested in it) untouched; its
+	// `merge:"default=..."` value is not applied either. MergeFromMap
+	// only calls it for fields assigned from map values, not for the
+	// structs that nested maps are merged into.
+	FieldPredicate func(path string, dst, src reflect.Value) bool
+
+	// OnFieldSet, if set, is called after a field has been written with a
+	// value different from its previous one.
+	OnFieldSet func(path string, oldVal, newVal reflect.Value)
+
+	// Logger, if set, receives a debug record for every field considered,
+	// with the field path, the action taken (set, append, merge or skip),
+	// the merge option and, for plain values, the old and new values. The
+	// values of masked fields are redacted.
+	Logger *slog.Logger
+
+	// DryRun computes the merge without modifying the destination.
+	// OnFieldSet is still called for every field that would change, and
+	// Merger implementations run on copies of the destination fields so
+	// that their errors are reported. See MergeDryRun.
+	DryRun bool
+
+	// UseJSONTags allows Include and Exclude paths to name fields by their
+	// `json` tag names as well as by their Go names, e.g.
+	// "address.postal_code" for Address.PostalCode.
+	UseJSONTags bool
+
+	// FieldRenamer, if set, allows Include and Exclude paths to name fields
+	// by the names it returns for their Go names, e.g. strings.ToLower for
+	// "address.street". Like UseJSONTags, which it can be combined with, it
+	// only changes how paths are resolved.
+	FieldRenamer func(structFieldName string) string
+
+	// ContinueOnError makes the merge carry on with the remaining fields
+	// when merging a field fails, for example because a Merger returned an
+	// error. All the errors are then returned together in a MultiError.
+	// By default the merge stops at the first error.
+	ContinueOnError bool
+
+	// OnError, if set, is called with the path of a field whose merge
+	// failed, for example because a Merger returned an error, and takes
+	// precedence over ContinueOnError. Returning nil skips the field and
+	// goes on with the merge; returning an error, such as err itself, stops
+	// the merge with that error.
+	OnError func(path string, err error) error
+
+	// VersionField is the dot-separated path of the integer field holding
+	// the version of a struct, read by MergeWithVersion.
+	VersionField string
+
+	// StrictKeys makes MergeFromMap fail with ErrUnknownKey for map keys
+	// that do not match any field, instead of ignoring them.
+	StrictKeys bool
+
+	// Strict makes MergeCompatible fail with ErrTypeMismatch for fields
+	// present in both structs whose types are incompatible, instead of
+	// skipping them.
+	Strict bool
+
+	// IgnoreTypeMismatch makes MergeCompatible skip fields whose types are
+	// incompatible even when Strict is set, so that a shared strict Config
+	// can merge between generated and hand-written structs whose field
+	// types differ slightly. Skipped fields are logged with the reason.
+	IgnoreTypeMismatch bool
+
+	// MapMergeStrategy controls how map fields are merged.
+	// The default is MapReplace.
+	MapMergeStrategy MapMergeStrategy
+
+	// InterfaceMergeStrategy controls how interface-typed fields are
+	// merged. The default is InterfaceReplace.
+	InterfaceMergeStrategy InterfaceMergeStrategy
+
+	// ByteSliceMergeStrategy controls how []byte fields, and other slices
+	// of bytes, are merged. The default is ByteSliceReplace.
+	ByteSliceMergeStrategy ByteSliceMergeStrategy
+
+	// KeepZeroDurations makes zero time.Duration values count as set
+	// rather than empty, for fields where zero is meaningful (e.g. "no
+	// delay"). ExcludeEmpty then copies them and OverwriteEmpty leaves them
+	// in place. RegisterZeroChecker offers the same for any type.
+	KeepZeroDurations bool
+
+	// KeepFalseBools makes false booleans count as set rather than empty,
+	// for fields where false is a meaningful value. ExcludeEmpty then
+	// copies them, OverwriteEmpty leaves them in place and FillEmpty never
+	// fills them, whatever TreatBoolFalseAsZero says.
+	KeepFalseBools bool
+
+	// DereferencePointers makes a non-nil pointer count as empty when the
+	// value it points to is empty, so that ExcludeEmpty skips a *string
+	// pointing to "" as it skips a nil one. By default only nil pointers
+	// are empty, letting pointers tell "not provided" from "set to zero".
+	DereferencePointers bool
+
+	// TreatBoolFalseAsZero lets the FillEmpty option, and so
+	// MergeWithDefaults, overwrite false bools in the destination. By
+	// default a false bool is taken to be deliberately set.
+	TreatBoolFalseAsZero bool
+
+	// SliceKeyField names the field used by MergeSlice to match source
+	// elements to destination elements. If empty, elements are matched by
+	// index.
+	SliceKeyField string
+
+	// MaxDepth limits how many levels of nested structs are merged field by
+	// field; top-level fields are at depth 1. Zero means no limit.
+	// MaxDepthBehavior decides what happens to structs below the limit.
+	MaxDepth         int
+	MaxDepthBehavior MaxDepthBehavior
+
+	// DeepCopyPointers makes pointer fields point to new copies of the
+	// values the source points to, instead of sharing them with the
+	// source. The copies are complete: the merge option and paths only
+	// decide whether a pointer field is set, not what is copied.
+	DeepCopyPointers bool
+
+	// UseUnsafe merges unexported fields too, reading and writing them
+	// through package unsafe. It only takes effect when the package is
+	// built with the structmerge_unsafe build tag and is ignored otherwise.
+	//
+	// WARNING: this bypasses Go's visibility rules. It can break the
+	// invariants of types from other packages, copy values such as
+	// sync.Mutex that must not be copied, and may stop working with any
+	// release of Go. Only use it for types you control.
+	UseUnsafe bool
 }
 
 // Merge combines two structs of the same type based on the provided configuration
 // The default configuration is to include all fields.
 func Merge(dst, src interface{}, cfg ...Config) error {
-	defaultConfig := Config{Option: IncludeAll}
+	return mergeValues(newMergeState(context.Background()), reflect.ValueOf(dst), reflect.ValueOf(src), configOf(cfg), "")
+}
+
+// MergeWithContext is like Merge but stops as soon as ctx is cancelled or its
+// deadline is exceeded, returning ctx.Err(). The context is checked before
+// each nested struct is merged, so dst may be left partially merged and
+// should be discarded on error.
+func MergeWithContext(ctx context.Context, dst, src interface{}, cfg ...Config) error {
+	return mergeValues(newMergeState(ctx), reflect.ValueOf(dst), reflect.ValueOf(src), configOf(cfg), "")
+}
+
+// MergeMany merges each of srcs into dst from left to right using the same
+// configuration, so later sources take precedence over earlier ones.
+// It stops at the first error. Empty-ness checks are evaluated against
+// each source individually.
+func MergeMany(dst interface{}, srcs []interface{}, cfg ...Config) error {
+	config := configOf(cfg)
+	state := newMergeState(context.Background())
+	dstValue := reflect.ValueOf(dst)
+	for _, src := range srcs {
+		if err := mergeValues(state, dstValue, reflect.ValueOf(src), config, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeWithDefaults fills the empty fields of dst with the non-empty values
+// of defaults, leaving fields that are already set untouched. It merges with
+// the FillEmpty option, whatever the option of cfg.
+func MergeWithDefaults(dst, defaults interface{}, cfg ...Config) error {
+	config := configOf(cfg)
+	config.Option = FillEmpty
+	return Merge(dst, defaults, config)
+}
+
+// MustMerge is like Merge but panics if the merge fails.
+// The panic value is the error returned by Merge.
+func MustMerge(dst, src interface{}, cfg ...Config) {
+	if err := Merge(dst, src, cfg...); err != nil {
+		panic(err)
+	}
+}
+
+// configOf returns the first config in cfg or the default configuration.
+func configOf(cfg []Config) Config {
 	if len(cfg) > 0 {
-		defaultConfig = cfg[0]
+		return cfg[0]
+	}
+	return Config{Option: IncludeAll}
+}
+
+// mergeState holds the state shared by the recursive calls of a merge.
+type mergeState struct {
+	ctx context.Context
+
+	// visited holds the addresses of the source pointers currently being
+	// copied, to detect cycles.
+	visited map[uintptr]bool
+
+	// result, if set, collects the fields seen by MergeWithResult.
+	result *MergeResult
+
+	// errs collects field errors under Config.ContinueOnError.
+	errs MultiError
+
+	// aborted is set once Config.OnError has returned an error, so that the
+	// error is not handled again while it propagates to the top level.
+	aborted bool
+
+	// nullAsZero makes MergePatch reset fields given a nil map value.
+	nullAsZero bool
+
+	// deepCopy makes DeepCopy and Revert copy the contents of slice, map,
+	// array and interface fields rather than merge them.
+	deepCopy bool
+
+	// filter selects the fields to merge. It is built from the Config once
+	// per top-level merge rather than for every nested struct.
+	filter pathFilter
+}
+
+func newMergeState(ctx context.Context) *mergeState {
+	return &mergeState{ctx: ctx}
+}
+
+// fieldError handles the error err raised while merging the field at path.
+// If cfg.OnError is set, its result is returned. Under cfg.ContinueOnError
+// err is collected and nil is returned so that the merge goes on. Otherwise,
+// or if the context is done, err is returned.
+func (s *mergeState) fieldError(cfg Config, path string, err error) error {
+	if s.aborted || s.ctx.Err() != nil {
+		return err
+	}
+	if cfg.OnError != nil {
+		if err := cfg.OnError(path, err); err != nil {
+			s.aborted = true
+			return err
+		}
+		return nil
 	}
-	return mergeValues(reflect.ValueOf(dst), reflect.ValueOf(src), defaultConfig, "")
+	if !cfg.ContinueOnError {
+		return err
+	}
+	s.errs = append(s.errs, err)
+	return nil
 }
 
-func mergeValues(dst, src reflect.Value, cfg Config, prefix string) error {
+// collected returns the errors collected by fieldError as a MultiError, or
+// nil if there are none, and resets them.
+func (s *mergeState) collected() error {
+	if len(s.errs) == 0 {
+		return nil
+	}
+	errs := s.errs
+	s.errs = nil
+	return errs
+}
+
+// countField records that a field was considered for merging.
+func (s *mergeState) countField() {
+	if s.result != nil {
+		s.result.FieldCount++
+	}
+}
+
+func mergeValues(state *mergeState, dst, src reflect.Value, cfg Config, prefix string) error {
+	if err := state.ctx.Err(); err != nil {
+		return err
+	}
+
 	if dst.Kind() != reflect.Ptr || dst.Elem().Kind() != reflect.Struct {
 		return ErrInvalidDestination
 	}
@@ -74,113 +660,871 @@ func mergeValues(dst, src reflect.Value, cfg Config, prefix string) error {
 	dst = dst.Elem()
 
 	if src.Kind() != reflect.Struct {
-		return ErrInvalidSource
+		return wrapError(prefix, ErrInvalidSource)
 	}
 
 	if dst.Type() != src.Type() {
-		return ErrTypeMismatch
+		return wrapError(prefix, ErrTypeMismatch)
+	}
+
+	if prefix == "" {
+		cfg = resolveConfig(dst.Type(), cfg)
+		state.aborted = false
+		state.filter = newPathFilter(cfg)
+		state.warnUnknownPaths(cfg, dst.Type())
 	}
 
+	meta := cachedMeta(dst.Type())
+
 	// Check if it's time.Time and copy it directly
-	if dst.CanInterface() {
-		if _, ok := dst.Interface().(time.Time); ok {
-			dst.Set(src)
+	if meta.isTime {
+		dst.Set(src)
+		return nil
+	}
+
+	// Check if a struct implements the Merger interface
+	if meta.merger {
+		return wrapError(prefix, mergeMerger(state, dst, src, cfg, strings.TrimSuffix(prefix, ".")))
+	}
+
+	// Unexported fields can only be exposed on addressable values.
+	if cfg.UseUnsafe && !src.CanAddr() {
+		src = cloneValue(src)
+	}
+
+	for _, field := range meta.fields {
+		fullFieldName := prefix + field.name
+
+		if field.masked {
+			state.logMasked(cfg, fullFieldName)
+			continue
+		}
+
+		// Check if field should be included or excluded
+		if state.filter.skip(fullFieldName, field.nested || field.ptr) {
+			continue
+		}
+
+		dstField := dst.FieldByIndex(field.index)
+		srcField := src.FieldByIndex(field.index)
+		if !field.exported && cfg.UseUnsafe {
+			dstField = exposeField(dstField)
+			srcField = exposeField(srcField)
+		}
+
+		merged, err := mergeStructField(state, dstField, srcField, field, cfg, fullFieldName)
+		if merged && err == nil {
+			err = applyDefault(state, dstField, srcField, field.opts, cfg, fullFieldName)
+		}
+		if err != nil {
+			if err := state.fieldError(cfg, fullFieldName, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if prefix == "" {
+		return state.collected()
+	}
+	return nil
+}
+
+// mergeStructField merges srcField into dstField, the field described by
+// field at path, unless FieldPredicate rejects it. It reports whether the
+// field was merged, so that a rejected field is left entirely untouched.
+func mergeStructField(state *mergeState, dstField, srcField reflect.Value, field fieldMeta, cfg Config, path string) (bool, error) {
+	if cfg.FieldPredicate != nil && !cfg.FieldPredicate(path, dstField, srcField) {
+		return false, nil
+	}
+	return true, mergeSelectedField(state, dstField, srcField, field, cfg, path)
+}
+
+// mergeSelectedField merges srcField into dstField, applying registered
+// merge functions, Merger, MaxDepth and nested struct merging.
+func mergeSelectedField(state *mergeState, dstField, srcField reflect.Value, field fieldMeta, cfg Config, path string) error {
+	// Functions registered for the field type take precedence.
+	if fn, ok := mergeFunc(dstField.Type()); ok && dstField.CanSet() {
+		state.countField()
+		if err := mergeWith(state, dstField, srcField, cfg, path, fn); err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	// Check if a specific field implements merger
+	if field.merger {
+		state.countField()
+		if err := mergeMerger(state, dstField, srcField, cfg, path); err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		return nil
+	}
+
+	// Only set if the field is settable
+	if !dstField.CanSet() {
+		return nil
+	}
+
+	if cfg.Option == NilToEmpty && isStructPointer(dstField.Type()) && !srcField.IsNil() {
+		tooDeep, err := belowMaxDepth(cfg, path)
+		if err != nil {
+			return err
+		}
+		if !tooDeep {
+			return mergeStructPointer(state, dstField, srcField, cfg, path)
+		}
+	}
+
+	// When Include or Exclude select some of the fields of a struct pointed
+	// to only, those fields are merged into a copy of the destination
+	// struct, or a new one if the pointer is nil, so that memory shared with
+	// other values is left untouched.
+	if isStructPointer(dstField.Type()) && !srcField.IsNil() && state.filter.partial(path) {
+		tooDeep, err := belowMaxDepth(cfg, path)
+		if err != nil {
+			return err
+		}
+		if !tooDeep {
+			ptr := reflect.New(dstField.Type().Elem())
+			if !dstField.IsNil() {
+				ptr.Elem().Set(dstField.Elem())
+			}
+			if err := mergeStructPointer(state, ptr, srcField, cfg, path); err != nil {
+				return err
+			}
+			// A nil pointer stays nil if no selected field gave it a value.
+			if !cfg.DryRun && (!dstField.IsNil() || !ptr.Elem().IsZero()) {
+				dstField.Set(ptr)
+			}
 			return nil
 		}
 	}
 
-	// Check if a struct implements the Merger interface
-	ifacetype := reflect.TypeOf((*Merger)(nil)).Elem()
-	if dst.CanAddr() && dst.Addr().Type().Implements(ifacetype) {
-		merger := dst.Addr().Interface().(Merger)
-		return merger.Merge(src)
+	// Nested structs below MaxDepth are merged as a whole.
+	if field.nested {
+		tooDeep, err := belowMaxDepth(cfg, path)
+		if err != nil {
+			return err
+		}
+		if tooDeep {
+			return mergeField(state, dstField, srcField, field.opts, cfg, path)
+		}
 	}
 
-	includeMap := make(map[string]bool)
-	for _, f := range cfg.Include {
-		includeMap[f] = true
+	// `merge:"strategy=latest"` and `merge:"strategy=earliest"` pick one
+	// of two timestamps. The tag is ignored on fields of other types.
+	if strategy, ok := field.opts.Value("strategy"); ok && dstField.Type() == timeType {
+		if pick, ok := timeStrategies[strategy]; ok {
+			state.countField()
+			return mergeWith(state, dstField, srcField, cfg, path, func(dst, src reflect.Value) error {
+				if pick(dst.Interface().(time.Time), src.Interface().(time.Time)) {
+					dst.Set(src)
+				}
+				return nil
+			})
+		}
 	}
 
-	excludeMap := make(map[string]bool)
-	for _, f := range cfg.Exclude {
-		excludeMap[f] = true
+	// Handle nested struct merging. time.Time is handled by mergeValues.
+	// Immutable structs are compared and set as a whole by mergeField.
+	if (field.nested || dstField.Type() == timeType) && !field.opts.Contains("immutable") {
+		// Recursively merge nested structs
+		return mergeValues(state, dstField.Addr(), srcField, cfg, path+".")
 	}
 
-	for i := 0; i < dst.NumField(); i++ {
-		field := dst.Type().Field(i)
-		fieldName := field.Name
-		fullFieldName := prefix + fieldName
+	return mergeField(state, dstField, srcField, field.opts, cfg, path)
+}
 
-		// Check if field should be included or excluded
-		if len(cfg.Include) > 0 && !shouldInclude(fullFieldName, includeMap) {
-			continue // Skip if not included
+// isStructPointer reports whether t is a pointer to a struct that is merged
+// field by field.
+func isStructPointer(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isNestedStruct(t.Elem())
+}
+
+// mergeStructPointer merges the struct the non-nil src points to into the
+// struct dst points to, allocating it if dst is nil. A src that leads back
+// to a struct being merged yields ErrCyclicReference.
+func mergeStructPointer(state *mergeState, dst, src reflect.Value, cfg Config, path string) error {
+	addr := src.Pointer()
+	if state.visited[addr] {
+		return &FieldError{Path: path, Err: ErrCyclicReference}
+	}
+	if state.visited == nil {
+		state.visited = make(map[uintptr]bool)
+	}
+	state.visited[addr] = true
+	defer delete(state.visited, addr)
+
+	// In dry-run mode a nil pointer is allocated on a scratch copy.
+	if dst.IsNil() {
+		if cfg.DryRun {
+			dst = cloneValue(dst)
 		}
+		dst.Set(reflect.New(dst.Type().Elem()))
+	}
+	return mergeValues(state, dst, src.Elem(), cfg, path+".")
+}
+
+// mergeField merges the non-struct value srcField into the settable
+// dstField according to the merge option, the field's tag options and cfg.
+func mergeField(state *mergeState, dstField, srcField reflect.Value, opts tagOptions, cfg Config, path string) error {
+	state.countField()
 
-		if excludeMap[fullFieldName] {
-			continue // Skip if excluded
+	if _, ok := cfg.Transformers[path]; !ok {
+		srcField = transformKind(srcField, cfg)
+	}
+
+	shouldSet := true
+	switch cfg.Option {
+	case ExcludeEmpty:
+		shouldSet = !isEmpty(srcField, cfg)
+	case OverwriteEmpty:
+		shouldSet = isEmpty(dstField, cfg)
+	case OverwriteNonEmpty:
+		shouldSet = !isEmpty(dstField, cfg)
+	case KeepFirst:
+		shouldSet = isUnset(dstField, cfg)
+	case FillEmpty:
+		shouldSet = !isEmpty(srcField, cfg) && isEmpty(dstField, cfg) &&
+			(dstField.Kind() != reflect.Bool || cfg.TreatBoolFalseAsZero)
+	case ExcludeZeroInDst, SymmetricDifference:
+		shouldSet = !isEmpty(srcField, cfg) && isEmpty(dstField, cfg)
+	case MaxIntWins:
+		if c, ok := compareNumbers(srcField, dstField); ok {
+			shouldSet = c > 0
+		}
+	case MinIntWins:
+		if c, ok := compareNumbers(srcField, dstField); ok {
+			shouldSet = c < 0
 		}
+	case IntersectionOnly:
+		shouldSet = !isEmpty(srcField, cfg) && !isEmpty(dstField, cfg)
+	}
 
-		dstField := dst.Field(i)
-		srcField := src.Field(i)
+	// `merge:"omitempty"` skips empty source values for this field only.
+	if opts.Contains("omitempty") && isEmpty(srcField, cfg) {
+		shouldSet = false
+	}
 
-		// Check if a specific field implements merger
-		if dstField.CanAddr() && dstField.Addr().Type().Implements(ifacetype) {
-			merger := dstField.Addr().Interface().(Merger)
-			merger.Merge(srcField)
-			continue
+	if !shouldSet {
+		if state.result != nil {
+			state.result.Skipped = append(state.result.Skipped, path)
 		}
+		state.logField(cfg, path, "skip", dstField, srcField)
+		return nil
+	}
 
-		// Only set if the field is settable
-		if !dstField.CanSet() {
-			continue
+	value := srcField
+	if transform, ok := cfg.Transformers[path]; ok {
+		value = transform(dstField, srcField)
+	} else if cfg.ConflictResolver != nil && !isEmpty(dstField, cfg) && !isEmpty(srcField, cfg) {
+		value = cfg.ConflictResolver(path, dstField, srcField)
+		if !value.IsValid() || value.IsZero() {
+			state.logField(cfg, path, "skip", dstField, srcField)
+			return nil
+		}
+	}
+
+	// `merge:"immutable"` fields may be set once but never changed. Structs
+	// such as time.Time are unset when all their fields are zero.
+	if opts.Contains("immutable") && !isZero(dstField) && !dstField.IsZero() && !sameValue(dstField, value) {
+		return &FieldError{Path: path, Err: ErrImmutableField}
+	}
+
+	// In dry-run mode, and for fields whose result must be validated, the
+	// new value is computed on a scratch copy.
+	expr, validate := validateExpr(opts)
+	target := dstField
+	if cfg.DryRun || validate {
+		target = cloneValue(dstField)
+	}
+
+	var oldVal reflect.Value
+	if cfg.OnFieldSet != nil || cfg.Logger != nil {
+		oldVal = cloneValue(dstField)
+	}
+
+	keyField, byKey := opts.Value("key")
+
+	action := "set"
+	switch {
+	case state.deepCopy && isContainer(target.Kind()):
+		if err := copyValue(state, target, value, cfg, path); err != nil {
+			return err
+		}
+	case byKey && target.Kind() == reflect.Slice:
+		// `merge:"key:Field"` merges slice elements with equal Field values.
+		action = "merge"
+		if err := mergeByKey(state, target, value, fieldKey(keyField), cfg, path); err != nil {
+			return err
+		}
+	case opts.Contains("append") && target.Kind() == reflect.Slice:
+		// `merge:"append"` appends source elements to a slice field
+		// instead of replacing it.
+		action = "append"
+		appendSlice(target, value)
+	case cfg.Option == AppendSlices && target.Kind() == reflect.Slice &&
+		!opts.Contains("replace") && target.Type() != rawMessageType:
+		action = "append"
+		appendSlice(target, value)
+	case cfg.ByteSliceMergeStrategy == ByteSliceAppend && isByteSlice(target.Type()):
+		action = "append"
+		appendSlice(target, value)
+	case cfg.MapMergeStrategy != MapReplace && target.Kind() == reflect.Map:
+		action = "merge"
+		if err := mergeMap(state, target, value, cfg, path); err != nil {
+			return err
+		}
+	case cfg.InterfaceMergeStrategy == InterfaceMergeDeep && target.Kind() == reflect.Interface:
+		action = "merge"
+		if err := mergeInterface(state, target, value, cfg, path); err != nil {
+			return err
 		}
+	case cfg.DeepCopyPointers && target.Kind() == reflect.Ptr:
+		if err := copyPointer(state, target, value, cfg, path); err != nil {
+			return err
+		}
+	default:
+		target.Set(value)
+	}
+
+	// `merge:"validate=..."` rejects results before they are assigned.
+	if validate {
+		if err := validateValue(expr, target); err != nil {
+			return &ValidationError{Path: path, Expr: expr, Value: target.Interface(), Err: err}
+		}
+		if !cfg.DryRun {
+			dstField.Set(target)
+		}
+	}
+
+	state.logField(cfg, path, action, oldVal, target)
+	if cfg.OnFieldSet != nil && !reflect.DeepEqual(oldVal.Interface(), target.Interface()) {
+		cfg.OnFieldSet(path, oldVal, target)
+	}
+	return nil
+}
+
+// transformKind applies the Config.Transform function registered for the
+// kind of v, converting its result back to the type of v.
+func transformKind(v reflect.Value, cfg Config) reflect.Value {
+	fn, ok := cfg.Transform[v.Kind()]
+	if !ok {
+		return v
+	}
+	out := fn(v)
+	if out.Type() != v.Type() {
+		out = out.Convert(v.Type())
+	}
+	return out
+}
+
+// applyDefault sets dstField to the value of its `merge:"default=..."` tag
+// option if the merge left it zero. In dry-run mode, where dstField keeps its
+// old value, the default applies when both dstField and srcField are zero.
+func applyDefault(state *mergeState, dstField, srcField reflect.Value, opts tagOptions, cfg Config, path string) error {
+	text, ok := opts.Value("default")
+	if !ok || !dstField.CanSet() || !isZero(dstField) || (cfg.DryRun && !isZero(srcField)) {
+		return nil
+	}
+
+	target := dstField
+	if cfg.DryRun {
+		target = cloneValue(dstField)
+	}
+	oldVal := cloneValue(dstField)
+
+	if err := parseText(target, text); err != nil {
+		return &FieldError{Path: path, Err: err}
+	}
 
-		// Handle nested struct merging
-		if dstField.Kind() == reflect.Struct {
-			// Recursively merge nested structs
-			err := mergeValues(dstField.Addr(), srcField, cfg, fullFieldName+".")
-			if err != nil {
+	state.logField(cfg, path, "default", oldVal, target)
+	if cfg.OnFieldSet != nil && !reflect.DeepEqual(oldVal.Interface(), target.Interface()) {
+		cfg.OnFieldSet(path, oldVal, target)
+	}
+	return nil
+}
+
+// compareNumbers compares a and b, of the same numeric type, returning -1,
+// 0 or +1 as a is less than, equal to or greater than b. ok is false for
+// values of other kinds.
+func compareNumbers(a, b reflect.Value) (int, bool) {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float()), true
+	}
+	return 0, false
+}
+
+// sameValue reports whether a and b, of the same type, hold equal values.
+// time.Time values are compared by instant.
+func sameValue(a, b reflect.Value) bool {
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// mergeMerger merges src into the addressable dst, whose pointer implements
+// MergerWithContext or Merger. See mergeWith.
+func mergeMerger(state *mergeState, dst, src reflect.Value, cfg Config, path string) error {
+	return mergeWith(state, dst, src, cfg, path, func(dst, src reflect.Value) error {
+		if m, ok := dst.Addr().Interface().(MergerWithContext); ok {
+			if err := state.ctx.Err(); err != nil {
 				return err
 			}
+			return m.MergeCtx(state.ctx, src)
+		}
+		return dst.Addr().Interface().(Merger).Merge(src)
+	})
+}
+
+// isByteSlice reports whether t is a slice of bytes other than
+// json.RawMessage.
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t != rawMessageType
+}
+
+// timeStrategies maps the values of the `merge:"strategy=..."` tag option to
+// functions reporting whether the source timestamp replaces the destination
+// one. A zero timestamp is never picked over a non-zero one.
+var timeStrategies = map[string]func(dst, src time.Time) bool{
+	"latest": func(dst, src time.Time) bool {
+		return src.After(dst)
+	},
+	"earliest": func(dst, src time.Time) bool {
+		return !src.IsZero() && (dst.IsZero() || src.Before(dst))
+	},
+}
+
+// mergeWith merges src into the addressable dst by calling merge. In dry-run
+// mode merge runs on a copy of dst. OnFieldSet is called with path if the
+// value changed.
+func mergeWith(state *mergeState, dst, src reflect.Value, cfg Config, path string, merge func(dst, src reflect.Value) error) error {
+	target := dst
+	if cfg.DryRun {
+		target = cloneValue(dst)
+	}
+
+	var oldVal reflect.Value
+	if cfg.OnFieldSet != nil || cfg.Logger != nil {
+		oldVal = cloneValue(dst)
+	}
+
+	if err := merge(target, src); err != nil {
+		return err
+	}
+
+	state.logField(cfg, path, "merge", oldVal, target)
+
+	if cfg.OnFieldSet != nil && !reflect.DeepEqual(oldVal.Interface(), target.Interface()) {
+		cfg.OnFieldSet(path, oldVal, target)
+	}
+	return nil
+}
+
+// cloneValue returns an addressable shallow copy of v.
+func cloneValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// appendSlice sets the slice dst to a new slice holding its elements
+// followed by those of src, so that the spare capacity of dst, which may be
+// shared with other slices, is never written to. An empty src leaves dst
+// untouched, so a nil dst stays nil.
+func appendSlice(dst, src reflect.Value) {
+	if src.Len() == 0 {
+		return
+	}
+	n := dst.Len()
+	merged := reflect.MakeSlice(dst.Type(), n+src.Len(), n+src.Len())
+	reflect.Copy(merged, dst)
+	reflect.Copy(merged.Slice(n, merged.Len()), src)
+	dst.Set(merged)
+}
+
+// belowMaxDepth reports whether the fields of the struct at path lie below
+// cfg.MaxDepth, in which case the struct must be merged as a whole. Under
+// MaxDepthError it returns ErrMaxDepthExceeded instead.
+func belowMaxDepth(cfg Config, path string) (bool, error) {
+	if cfg.MaxDepth <= 0 || strings.Count(path, ".")+2 <= cfg.MaxDepth {
+		return false, nil
+	}
+	if cfg.MaxDepthBehavior == MaxDepthError {
+		return false, &FieldError{Path: path, Err: ErrMaxDepthExceeded}
+	}
+	return true, nil
+}
+
+// mergeInterface merges the concrete value held by src into the one held by
+// dst if both are structs, or non-nil pointers to structs, of the same type,
+// or maps of the same type under MapMergeDeep. Otherwise dst is replaced by
+// src. The merged value is stored in a new
+// allocation, so values shared with other interfaces are never modified.
+func mergeInterface(state *mergeState, dst, src reflect.Value, cfg Config, path string) error {
+	if dst.IsNil() || src.IsNil() || dst.Elem().Type() != src.Elem().Type() {
+		dst.Set(src)
+		return nil
+	}
+
+	dstVal, srcVal := dst.Elem(), src.Elem()
+	isPtr := dstVal.Kind() == reflect.Ptr
+	if isPtr {
+		if dstVal.IsNil() || srcVal.IsNil() {
+			dst.Set(src)
+			return nil
+		}
+		dstVal, srcVal = dstVal.Elem(), srcVal.Elem()
+	}
+
+	// Maps held by interfaces, as in a map[string]interface{}, are merged
+	// when merging maps deeply.
+	if !isPtr && dstVal.Kind() == reflect.Map && cfg.MapMergeStrategy == MapMergeDeep {
+		m := cloneValue(dstVal)
+		if err := mergeMap(state, m, srcVal, cfg, path); err != nil {
+			return err
+		}
+		dst.Set(m)
+		return nil
+	}
+
+	if dstVal.Kind() != reflect.Struct {
+		dst.Set(src)
+		return nil
+	}
+
+	tooDeep, err := belowMaxDepth(cfg, path)
+	if err != nil {
+		return err
+	}
+	if tooDeep {
+		dst.Set(src)
+		return nil
+	}
+
+	// Values held by an interface are not addressable, so merge into a copy.
+	elem := reflect.New(dstVal.Type())
+	elem.Elem().Set(dstVal)
+	if err := mergeValues(state, elem, srcVal, cfg, path+"."); err != nil {
+		return err
+	}
+
+	if isPtr {
+		dst.Set(elem)
+	} else {
+		dst.Set(elem.Elem())
+	}
+	return nil
+}
+
+// mergeMap merges the entries of the src map into the dst map according to
+// cfg.MapMergeStrategy. The result is stored in a new map, so a nil dst is
+// initialized and maps shared with dst are never modified.
+// Under MapMergeDeep, struct and map values present in both maps are merged
+// recursively with the path "<path>.<key>", and so are the values of
+// interface-typed maps such as map[string]interface{} (see mergeInterface).
+func mergeMap(state *mergeState, dst, src reflect.Value, cfg Config, path string) error {
+	if src.IsNil() {
+		return nil
+	}
+
+	// Maps can hold themselves through interface values.
+	addr := src.Pointer()
+	if state.visited[addr] {
+		return &FieldError{Path: path, Err: ErrCyclicReference}
+	}
+	if state.visited == nil {
+		state.visited = make(map[uintptr]bool)
+	}
+	state.visited[addr] = true
+	defer delete(state.visited, addr)
+
+	merged := reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+	iter := dst.MapRange()
+	for iter.Next() {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	iter = src.MapRange()
+	for iter.Next() {
+		key, srcVal := iter.Key(), iter.Value()
+
+		dstVal := merged.MapIndex(key)
+		if !dstVal.IsValid() {
+			merged.SetMapIndex(key, srcVal)
+			continue
+		}
+
+		if cfg.MapMergeStrategy != MapMergeDeep {
+			continue
+		}
+
+		switch srcVal.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Interface:
+		default:
+			merged.SetMapIndex(key, srcVal)
+			continue
+		}
+
+		elemPath := fmt.Sprintf("%s.%v", path, key.Interface())
+		tooDeep, err := belowMaxDepth(cfg, elemPath)
+		if err != nil {
+			return err
+		}
+		if tooDeep {
+			merged.SetMapIndex(key, srcVal)
+			continue
+		}
+
+		// Map values are not addressable, so merge into a copy.
+		elem := cloneValue(dstVal)
+		switch srcVal.Kind() {
+		case reflect.Struct:
+			err = mergeValues(state, elem.Addr(), srcVal, cfg, elemPath+".")
+		case reflect.Map:
+			err = mergeMap(state, elem, srcVal, cfg, elemPath)
+		case reflect.Interface:
+			err = mergeInterface(state, elem, srcVal, cfg, elemPath)
+		}
+		if err != nil {
+			return err
+		}
+		merged.SetMapIndex(key, elem)
+	}
+
+	dst.Set(merged)
+	return nil
+}
+
+// tagOptions is the comma-separated list of options in a `merge` struct tag.
+type tagOptions string
+
+// Contains reports whether the comma-separated list of options
+// contains the given option.
+func (o tagOptions) Contains(option string) bool {
+	s := string(o)
+	for s != "" {
+		var name string
+		name, s, _ = strings.Cut(s, ",")
+		if strings.TrimSpace(name) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the value of the option given as "option:value" or
+// "option=value" in the comma-separated list of options.
+func (o tagOptions) Value(option string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var name string
+		name, s, _ = strings.Cut(s, ",")
+		name = strings.TrimSpace(name)
+		if i := strings.IndexAny(name, ":="); i >= 0 && name[:i] == option {
+			return name[i+1:], true
+		}
+	}
+	return "", false
+}
+
+// pathFilter selects fields by path from the Include and Exclude lists.
+// Exact paths are looked up in maps; paths containing wildcards are compiled
+// once into patterns.
+type pathFilter struct {
+	include     map[string]bool
+	exclude     map[string]bool
+	includeGlob []pattern
+	excludeGlob []pattern
+	includeFunc func(path string) bool
+	excludeFunc func(path string) bool
+}
+
+func newPathFilter(cfg Config) pathFilter {
+	f := pathFilter{
+		include:     make(map[string]bool, len(cfg.Include)),
+		exclude:     make(map[string]bool, len(cfg.Exclude)),
+		includeFunc: cfg.IncludeFunc,
+		excludeFunc: cfg.ExcludeFunc,
+	}
+	if f.includeFunc != nil {
+		// IncludeFunc overrides the Include list.
+		cfg.Include = nil
+	} else if len(cfg.Groups) > 0 && len(cfg.Include) == 0 {
+		// No field belongs to the selected groups.
+		f.includeFunc = func(string) bool { return false }
+	}
+	for _, path := range cfg.Include {
+		if isGlob(path) {
+			f.includeGlob = append(f.includeGlob, compilePattern(path))
 		} else {
-			shouldSet := true
-			switch cfg.Option {
-			case ExcludeEmpty:
-				shouldSet = !isZero(srcField)
-			case OverwriteEmpty:
-				shouldSet = isZero(dstField)
+			f.include[path] = true
+		}
+	}
+	for _, path := range cfg.Exclude {
+		if isGlob(path) {
+			f.excludeGlob = append(f.excludeGlob, compilePattern(path))
+		} else {
+			f.exclude[path] = true
+		}
+	}
+	return f
+}
+
+// partial reports whether an Include or Exclude path names a field nested
+// in the field at path, so that its fields must be merged one by one.
+func (f pathFilter) partial(path string) bool {
+	if f.includeFunc != nil && !f.includeFuncMatch(path) {
+		return true // IncludeFunc may select nested fields only
+	}
+
+	prefix := path + "."
+	for _, paths := range []map[string]bool{f.include, f.exclude} {
+		for p := range paths {
+			if strings.HasPrefix(p, prefix) {
+				return true
 			}
+		}
+	}
 
-			if shouldSet || cfg.Option == IncludeAll {
-				dstField.Set(srcField)
+	if len(f.includeGlob)+len(f.excludeGlob) == 0 {
+		return false
+	}
+	segments := strings.Split(path, ".")
+	for _, globs := range [][]pattern{f.includeGlob, f.excludeGlob} {
+		for _, p := range globs {
+			if matchSegments(p, segments, true, false) && !p.match(segments) {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	return nil
+// skip reports whether the field at path is left out of the merge. nested
+// tells whether the fields nested in it are selected individually, in which
+// case it is kept if an include pattern may match one of them.
+func (f pathFilter) skip(path string, nested bool) bool {
+	if f.includeFunc != nil {
+		if !nested && !f.includeFuncMatch(path) {
+			return true
+		}
+	} else if len(f.include) > 0 || len(f.includeGlob) > 0 {
+		if !shouldInclude(path, f.include) && !f.includeGlobMatch(path, nested) {
+			return true // Skip if not included
+		}
+	}
+
+	if f.exclude[path] {
+		return true // Skip if excluded
+	}
+	if len(f.excludeGlob) > 0 {
+		segments := strings.Split(path, ".")
+		for _, p := range f.excludeGlob {
+			if p.match(segments) {
+				return true
+			}
+		}
+	}
+	return f.excludeFunc != nil && f.excludeFunc(path)
 }
 
+// includeFuncMatch reports whether includeFunc selects the field at path or
+// one of its parents.
+func (f pathFilter) includeFuncMatch(path string) bool {
+	for {
+		if f.includeFunc(path) {
+			return true
+		}
+		i := strings.LastIndexByte(path, '.')
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// includeGlobMatch reports whether the field at path, or one of its parents,
+// is matched by an include pattern. If nested is set, the field is also
+// selected when a pattern may match one of its nested fields.
+func (f pathFilter) includeGlobMatch(path string, nested bool) bool {
+	if len(f.includeGlob) == 0 {
+		return false
+	}
+	segments := strings.Split(path, ".")
+	for _, p := range f.includeGlob {
+		if matchSegments(p, segments, nested, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldInclude reports whether the field at fullFieldName is selected by the
+// include paths: either the field itself, one of its parents or one of its
+// nested fields is listed.
 func shouldInclude(fullFieldName string, includeMap map[string]bool) bool {
 	// Check if the exact full field name is in the include map
 	if includeMap[fullFieldName] {
 		return true
 	}
 
-	if !strings.Contains(fullFieldName, ".") {
-		for key := range includeMap {
-			if strings.HasPrefix(key, fullFieldName) {
-				return true
-			}
+	for key := range includeMap {
+		if strings.HasPrefix(key, fullFieldName+".") || strings.HasPrefix(fullFieldName, key+".") {
+			return true
 		}
 	}
 
 	return false
 }
 
+// isUnset reports whether v has never been given a value. Nillable kinds
+// are unset only when nil; all other kinds are unset when empty.
+func isUnset(v reflect.Value, cfg Config) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return isEmpty(v, cfg)
+}
+
+// isEmpty reports whether v counts as empty for the merge options,
+// taking the settings of cfg into account.
+func isEmpty(v reflect.Value, cfg Config) bool {
+	if cfg.KeepZeroDurations && v.Type() == durationType {
+		return false
+	}
+	if cfg.KeepFalseBools && v.Kind() == reflect.Bool {
+		return false
+	}
+	if cfg.DereferencePointers && v.Kind() == reflect.Ptr && !v.IsNil() {
+		return isEmpty(v.Elem(), cfg)
+	}
+	return isZero(v)
+}
+
 func isZero(v reflect.Value) bool {
+	if fn, ok := zeroChecker(v.Type()); ok {
+		return fn(v)
+	}
+
 	switch v.Kind() {
-	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+	case reflect.Map, reflect.Slice, reflect.String:
 		return v.Len() == 0
+	case reflect.Array:
+		// Fixed-size arrays are empty when all their elements are.
+		for i := 0; i < v.Len(); i++ {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
 	case reflect.Bool:
 		return !v.Bool()
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -189,9 +1533,27 @@ func isZero(v reflect.Value) bool {
 		return v.Uint() == 0
 	case reflect.Float32, reflect.Float64:
 		return v.Float() == 0
-	case reflect.Interface, reflect.Ptr:
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+	case reflect.Interface, reflect.Ptr, reflect.Func, reflect.Chan, reflect.UnsafePointer:
 		return v.IsNil()
+	case reflect.Struct:
+		// sql.NullString and friends are empty when not Valid.
+		if isNullType(v.Type()) {
+			return !v.FieldByName("Valid").Bool()
+		}
 	}
 
 	return false
 }
+
+// isNullType reports whether t is a nullable database type such as
+// sql.NullString or sql.Null[T]: a struct with a boolean Valid field that
+// implements driver.Valuer. Such types are merged as a whole.
+func isNullType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || !t.Implements(valuerType) {
+		return false
+	}
+	valid, ok := t.FieldByName("Valid")
+	return ok && valid.Type.Kind() == reflect.Bool
+}
@@ -1,10 +1,16 @@
 package structmerge
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+	"unicode"
 )
 
 type Address struct {
@@ -443,6 +449,96 @@ func TestMergeWithPointer(t *testing.T) {
 	}
 }
 
+func TestMergeNilToEmpty(t *testing.T) {
+	src := Person{Name: "Bob", Address: &Address{Street: "456 New St", City: "New City"}}
+
+	// A nil destination pointer gets its own allocation.
+	var dst Person
+	if err := Merge(&dst, src, Config{Option: NilToEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Fatalf("expected %#v, got %#v", src, dst)
+	}
+	if dst.Address == src.Address {
+		t.Fatal("expected Address to be a new allocation")
+	}
+
+	// An existing destination struct is merged into, zero fields included.
+	existing := &Address{Street: "123 Old St", City: "Old City", Country: "Uganda"}
+	dst = Person{Name: "Alice", Address: existing}
+	if err := Merge(&dst, src, Config{Option: NilToEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Address != existing || *existing != *src.Address {
+		t.Errorf("expected the existing Address to be updated, got %#v", dst.Address)
+	}
+
+	// A nil source pointer clears the destination.
+	if err := Merge(&dst, Person{Name: "Carol"}, Config{Option: NilToEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Address != nil {
+		t.Errorf("expected Address to be nil, got %#v", dst.Address)
+	}
+
+	// Dry runs leave a nil pointer nil.
+	dst = Person{}
+	if err := Merge(&dst, src, Config{Option: NilToEmpty, DryRun: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Address != nil {
+		t.Errorf("dry run allocated Address: %#v", dst.Address)
+	}
+
+	// Pointer cycles are reported.
+	n := &Node{Name: "self"}
+	n.Next = n
+	if err := Merge(&Node{}, *n, Config{Option: NilToEmpty}); !errors.Is(err, ErrCyclicReference) {
+		t.Errorf("expected ErrCyclicReference, got %v", err)
+	}
+}
+
+func TestMergeStructPointerPaths(t *testing.T) {
+	src := Person{Name: "Bob", Address: &Address{Street: "456 New St", City: "New City"}}
+
+	tests := []struct {
+		name     string
+		dst      *Address
+		cfg      Config
+		expected Address
+	}{
+		{"include nil", nil, Config{Include: []string{"Address.City"}}, Address{City: "New City"}},
+		{"include", &Address{Street: "1 Old St", Country: "Uganda"}, Config{Include: []string{"Address.City"}}, Address{Street: "1 Old St", City: "New City", Country: "Uganda"}},
+		{"exclude nil", nil, Config{Exclude: []string{"Address.Street"}}, Address{City: "New City"}},
+		{"exclude glob", &Address{Street: "1 Old St", Country: "Uganda"}, Config{Exclude: []string{"Address.C*"}}, Address{Street: "456 New St", Country: "Uganda"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := Person{Address: tt.dst}
+			if err := Merge(&dst, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst.Address == nil || dst.Address == src.Address {
+				t.Fatalf("expected Address to be allocated separately, got %p", dst.Address)
+			}
+			if *dst.Address != tt.expected {
+				t.Errorf("Address = %+v, want %+v", *dst.Address, tt.expected)
+			}
+		})
+	}
+
+	// Without nested paths the pointer is assigned as a whole.
+	var dst Person
+	if err := Merge(&dst, src, Config{Include: []string{"Address"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Address != src.Address {
+		t.Errorf("expected Address to be assigned, got %p", dst.Address)
+	}
+}
+
 type Floats struct {
 	Value  float32
 	Value2 float64
@@ -466,6 +562,560 @@ func TestMergeWithFloats(t *testing.T) {
 	}
 }
 
+type Complexes struct {
+	Value  complex64
+	Value2 complex128
+}
+
+func TestMergeWithComplexes(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   MergeOption
+		dst      Complexes
+		src      Complexes
+		expected Complexes
+	}{
+		{
+			name:     "IncludeAll",
+			option:   IncludeAll,
+			dst:      Complexes{Value: 1 + 2i, Value2: 3 + 4i},
+			src:      Complexes{Value2: 5 - 1i},
+			expected: Complexes{Value2: 5 - 1i},
+		},
+		{
+			name:     "ExcludeEmpty",
+			option:   ExcludeEmpty,
+			dst:      Complexes{Value: 1 + 2i, Value2: 3 + 4i},
+			src:      Complexes{Value2: 5 - 1i},
+			expected: Complexes{Value: 1 + 2i, Value2: 5 - 1i},
+		},
+		{
+			name:     "OverwriteEmpty",
+			option:   OverwriteEmpty,
+			dst:      Complexes{Value2: 3 + 4i},
+			src:      Complexes{Value: 1i, Value2: 5 - 1i},
+			expected: Complexes{Value: 1i, Value2: 3 + 4i},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, Config{Option: tt.option}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.dst != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, tt.dst)
+			}
+		})
+	}
+}
+
+type Device struct {
+	ID       [16]byte
+	Position [3]float64
+}
+
+func TestMergeWithArrays(t *testing.T) {
+	id := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	other := [16]byte{15: 1}
+
+	tests := []struct {
+		name     string
+		option   MergeOption
+		dst      Device
+		src      Device
+		expected Device
+	}{
+		{
+			name:     "IncludeAll",
+			option:   IncludeAll,
+			dst:      Device{ID: id, Position: [3]float64{1, 2, 3}},
+			src:      Device{ID: other},
+			expected: Device{ID: other},
+		},
+		{
+			name:     "ExcludeEmpty",
+			option:   ExcludeEmpty,
+			dst:      Device{ID: id, Position: [3]float64{1, 2, 3}},
+			src:      Device{Position: [3]float64{0, 0, 4}},
+			expected: Device{ID: id, Position: [3]float64{0, 0, 4}},
+		},
+		{
+			name:     "OverwriteEmpty",
+			option:   OverwriteEmpty,
+			dst:      Device{Position: [3]float64{1, 2, 3}},
+			src:      Device{ID: id, Position: [3]float64{4, 5, 6}},
+			expected: Device{ID: id, Position: [3]float64{1, 2, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, Config{Option: tt.option}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.dst != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, tt.dst)
+			}
+		})
+	}
+}
+
+type Locale struct {
+	TimeZone string        `merge:"default=UTC"`
+	Retries  int           `merge:"default=3"`
+	Timeout  time.Duration `merge:"default=30s"`
+	Debug    bool          `merge:"omitempty,default=true"`
+}
+
+func TestMergeDefaultTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   MergeOption
+		dst      Locale
+		src      Locale
+		expected Locale
+	}{
+		{
+			name:     "Both zero",
+			dst:      Locale{},
+			src:      Locale{},
+			expected: Locale{TimeZone: "UTC", Retries: 3, Timeout: 30 * time.Second, Debug: true},
+		},
+		{
+			name:     "Source set",
+			dst:      Locale{},
+			src:      Locale{TimeZone: "EAT", Retries: 5},
+			expected: Locale{TimeZone: "EAT", Retries: 5, Timeout: 30 * time.Second, Debug: true},
+		},
+		{
+			name:     "Destination set",
+			option:   ExcludeEmpty,
+			dst:      Locale{TimeZone: "EAT", Timeout: time.Second},
+			src:      Locale{},
+			expected: Locale{TimeZone: "EAT", Retries: 3, Timeout: time.Second, Debug: true},
+		},
+		{
+			name:     "Zeroed by the merge",
+			option:   IncludeAll,
+			dst:      Locale{TimeZone: "EAT"},
+			src:      Locale{},
+			expected: Locale{TimeZone: "UTC", Retries: 3, Timeout: 30 * time.Second, Debug: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, Config{Option: tt.option}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.dst != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, tt.dst)
+			}
+		})
+	}
+}
+
+func TestMergeDefaultTagDryRun(t *testing.T) {
+	dst := Locale{Retries: 1}
+	result, err := MergeWithResult(&dst, Locale{TimeZone: "EAT"}, Config{Option: ExcludeEmpty, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst != (Locale{Retries: 1}) {
+		t.Errorf("expected dst to be untouched, got %+v", dst)
+	}
+
+	expected := []string{"TimeZone", "Timeout", "Debug"}
+	if !reflect.DeepEqual(result.Changed, expected) {
+		t.Errorf("Changed = %v, want %v", result.Changed, expected)
+	}
+}
+
+func TestMergeDefaultTagPredicate(t *testing.T) {
+	// Fields rejected by FieldPredicate keep their value, defaults included.
+	cfg := Config{FieldPredicate: func(path string, dst, src reflect.Value) bool {
+		return path != "TimeZone"
+	}}
+
+	var dst Locale
+	if err := Merge(&dst, Locale{}, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Locale{Retries: 3, Timeout: 30 * time.Second, Debug: true}
+	if dst != expected {
+		t.Errorf("expected %+v, got %+v", expected, dst)
+	}
+}
+
+func TestMergeDefaultTagInvalid(t *testing.T) {
+	type Bad struct {
+		Port int `merge:"default=http"`
+	}
+
+	var dst Bad
+	err := Merge(&dst, Bad{})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Port" {
+		t.Errorf("expected a FieldError for Port, got %v", err)
+	}
+}
+
+type Invoice struct {
+	ID        int       `merge:"immutable"`
+	CreatedAt time.Time `merge:"immutable"`
+	Owner     Address   `merge:"immutable"`
+	Title     string
+}
+
+func TestMergeImmutableTag(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	base := Invoice{ID: 1, CreatedAt: created, Owner: Address{City: "Kampala"}, Title: "Draft"}
+
+	tests := []struct {
+		name     string
+		dst      Invoice
+		src      Invoice
+		cfg      Config
+		expected Invoice
+		errPath  string
+	}{
+		{
+			name:     "Set once",
+			dst:      Invoice{},
+			src:      base,
+			expected: base,
+		},
+		{
+			name:     "Same values",
+			dst:      base,
+			src:      Invoice{ID: 1, CreatedAt: created.In(time.FixedZone("EAT", 3*3600)), Owner: Address{City: "Kampala"}, Title: "Final"},
+			expected: Invoice{ID: 1, CreatedAt: created.In(time.FixedZone("EAT", 3*3600)), Owner: Address{City: "Kampala"}, Title: "Final"},
+		},
+		{
+			name:     "Empty source skipped",
+			dst:      base,
+			src:      Invoice{CreatedAt: created, Owner: Address{City: "Kampala"}, Title: "Final"},
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: Invoice{ID: 1, CreatedAt: created, Owner: Address{City: "Kampala"}, Title: "Final"},
+		},
+		{
+			name:    "Changed ID",
+			dst:     base,
+			src:     Invoice{ID: 2, CreatedAt: created, Owner: Address{City: "Kampala"}},
+			errPath: "ID",
+		},
+		{
+			name:    "Changed time",
+			dst:     base,
+			src:     Invoice{ID: 1, CreatedAt: created.Add(time.Second), Owner: Address{City: "Kampala"}},
+			errPath: "CreatedAt",
+		},
+		{
+			name:    "Changed struct",
+			dst:     base,
+			src:     Invoice{ID: 1, CreatedAt: created, Owner: Address{City: "Gulu"}},
+			errPath: "Owner",
+		},
+		{
+			name:    "Cleared",
+			dst:     base,
+			src:     Invoice{},
+			errPath: "ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Merge(&tt.dst, tt.src, tt.cfg)
+			if tt.errPath != "" {
+				var mergeErr *MergeError
+				if !errors.Is(err, ErrImmutableField) || !errors.As(err, &mergeErr) || mergeErr.Path != tt.errPath {
+					t.Fatalf("expected ErrImmutableField at %s, got %v", tt.errPath, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+type ProfilePatch struct {
+	Nickname *string
+	Age      *int
+	Public   *bool
+}
+
+func TestMergeDereferencePointers(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	flag := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		dst      ProfilePatch
+		src      ProfilePatch
+		expected ProfilePatch
+	}{
+		{
+			name:     "ExcludeEmpty copies pointers to zero values",
+			cfg:      Config{Option: ExcludeEmpty},
+			dst:      ProfilePatch{Nickname: str("al"), Age: num(30), Public: flag(true)},
+			src:      ProfilePatch{Nickname: str(""), Public: flag(false)},
+			expected: ProfilePatch{Nickname: str(""), Age: num(30), Public: flag(false)},
+		},
+		{
+			name:     "ExcludeEmpty skips pointers to zero values",
+			cfg:      Config{Option: ExcludeEmpty, DereferencePointers: true},
+			dst:      ProfilePatch{Nickname: str("al"), Age: num(30), Public: flag(true)},
+			src:      ProfilePatch{Nickname: str(""), Age: num(31), Public: flag(false)},
+			expected: ProfilePatch{Nickname: str("al"), Age: num(31), Public: flag(true)},
+		},
+		{
+			name:     "OverwriteEmpty fills pointers to zero values",
+			cfg:      Config{Option: OverwriteEmpty, DereferencePointers: true},
+			dst:      ProfilePatch{Nickname: str(""), Age: num(30)},
+			src:      ProfilePatch{Nickname: str("al"), Age: num(31), Public: flag(true)},
+			expected: ProfilePatch{Nickname: str("al"), Age: num(30), Public: flag(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+type Counter struct {
+	Node    string
+	Hits    int
+	Bytes   uint64
+	Ratio   float32
+	Latency float64
+}
+
+func TestMergeMinMaxIntWins(t *testing.T) {
+	dst := Counter{Node: "a", Hits: 5, Bytes: 100, Ratio: 0.5, Latency: 2.5}
+	src := Counter{Node: "b", Hits: 3, Bytes: 200, Ratio: 0.25, Latency: 4}
+
+	tests := []struct {
+		option   MergeOption
+		expected Counter
+	}{
+		{MaxIntWins, Counter{Node: "b", Hits: 5, Bytes: 200, Ratio: 0.5, Latency: 4}},
+		{MinIntWins, Counter{Node: "b", Hits: 3, Bytes: 100, Ratio: 0.25, Latency: 2.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.option.String(), func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, Config{Option: tt.option}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+
+	// Keyed counters merge CRDT-style.
+	counters := []Counter{{Node: "a", Hits: 5}, {Node: "b", Hits: 1}}
+	err := MergeByKey(&counters, []Counter{{Node: "b", Hits: 4}, {Node: "a", Hits: 2}, {Node: "c", Hits: 1}},
+		func(c Counter) string { return c.Node }, Config{Option: MaxIntWins})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Counter{{Node: "a", Hits: 5}, {Node: "b", Hits: 4}, {Node: "c", Hits: 1}}
+	if !reflect.DeepEqual(counters, expected) {
+		t.Errorf("MergeByKey() = %+v, want %+v", counters, expected)
+	}
+}
+
+type Hooks struct {
+	Name    string
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+	Events  chan string
+}
+
+func TestMergeFuncFields(t *testing.T) {
+	var calls []string
+	hook := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	events := make(chan string)
+
+	tests := []struct {
+		option MergeOption
+		start  string // hook expected in OnStart, or "" for nil
+		stop   string
+		events bool
+	}{
+		{IncludeAll, "src-start", "", false},
+		{ExcludeEmpty, "src-start", "dst-stop", true},
+		{OverwriteEmpty, "dst-start", "dst-stop", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.option.String(), func(t *testing.T) {
+			dst := Hooks{OnStart: hook("dst-start"), OnStop: hook("dst-stop"), Events: events}
+			src := Hooks{Name: "src", OnStart: hook("src-start")}
+			if tt.option == OverwriteEmpty {
+				dst.OnStop = nil
+				src.OnStop = hook("dst-stop")
+			}
+
+			if err := Merge(&dst, src, Config{Option: tt.option}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, c := range []struct {
+				fn   func(context.Context) error
+				want string
+			}{{dst.OnStart, tt.start}, {dst.OnStop, tt.stop}} {
+				if c.want == "" {
+					if c.fn != nil {
+						t.Error("expected a nil hook")
+					}
+					continue
+				}
+				calls = nil
+				if c.fn == nil {
+					t.Fatalf("expected hook %s, got nil", c.want)
+				}
+				_ = c.fn(context.Background())
+				if len(calls) != 1 || calls[0] != c.want {
+					t.Errorf("called %v, want %s", calls, c.want)
+				}
+			}
+			if (dst.Events != nil) != tt.events {
+				t.Errorf("Events = %v, want set = %v", dst.Events, tt.events)
+			}
+		})
+	}
+}
+
+type AuditRecord struct {
+	CreatedAt time.Time `merge:"strategy=earliest"`
+	UpdatedAt time.Time `merge:"strategy=latest"`
+	SeenAt    time.Time `merge:"strategy:latest"`
+	Note      string    `merge:"strategy=latest"`
+}
+
+func TestMergeTimeStrategy(t *testing.T) {
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		dst      AuditRecord
+		src      AuditRecord
+		expected AuditRecord
+	}{
+		{
+			name:     "Source is later",
+			dst:      AuditRecord{CreatedAt: t1, UpdatedAt: t1, SeenAt: t1, Note: "old"},
+			src:      AuditRecord{CreatedAt: t2, UpdatedAt: t2, SeenAt: t2, Note: "new"},
+			expected: AuditRecord{CreatedAt: t1, UpdatedAt: t2, SeenAt: t2, Note: "new"},
+		},
+		{
+			name:     "Source is earlier",
+			dst:      AuditRecord{CreatedAt: t2, UpdatedAt: t2, SeenAt: t2},
+			src:      AuditRecord{CreatedAt: t1, UpdatedAt: t1, SeenAt: t1},
+			expected: AuditRecord{CreatedAt: t1, UpdatedAt: t2, SeenAt: t2},
+		},
+		{
+			name:     "Zero destination",
+			src:      AuditRecord{CreatedAt: t1, UpdatedAt: t1},
+			expected: AuditRecord{CreatedAt: t1, UpdatedAt: t1},
+		},
+		{
+			name:     "Zero source",
+			dst:      AuditRecord{CreatedAt: t1, UpdatedAt: t1},
+			expected: AuditRecord{CreatedAt: t1, UpdatedAt: t1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changed []string
+			cfg := Config{OnFieldSet: func(path string, _, _ reflect.Value) {
+				changed = append(changed, path)
+			}}
+			if err := Merge(&tt.dst, tt.src, cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.dst != tt.expected {
+				t.Errorf("expected %+v, got %+v (changed %v)", tt.expected, tt.dst, changed)
+			}
+		})
+	}
+}
+
+// PrivateKey is never overwritten by a merge.
+type PrivateKey []byte
+
+func (PrivateKey) Mask() {}
+
+type Credentials struct {
+	User         string
+	PasswordHash string `merge:"mask"`
+	Key          PrivateKey
+}
+
+func TestMergeMask(t *testing.T) {
+	src := Credentials{User: "bob", PasswordHash: "new-hash", Key: PrivateKey("new-key")}
+
+	configs := []Config{
+		{},
+		{Option: OverwriteEmpty},
+		{Include: []string{"User", "PasswordHash", "Key"}},
+	}
+	for _, cfg := range configs {
+		dst := Credentials{User: "alice", PasswordHash: "hash", Key: PrivateKey("key")}
+		if err := Merge(&dst, src, cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dst.PasswordHash != "hash" || string(dst.Key) != "key" {
+			t.Errorf("masked fields were overwritten: %+v", dst)
+		}
+	}
+
+	var dst Credentials
+	if err := MergeFromMap(&dst, map[string]interface{}{"User": "bob", "PasswordHash": "x", "Key": []byte("y")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, Credentials{User: "bob"}) {
+		t.Errorf("masked fields were set from a map: %+v", dst)
+	}
+
+	// Copies still carry the masked values.
+	cp := Clone(Credentials{PasswordHash: "hash", Key: PrivateKey("key")})
+	if cp.PasswordHash != "hash" || string(cp.Key) != "key" {
+		t.Errorf("Clone() lost masked fields: %+v", cp)
+	}
+
+	names, err := FieldNames(Credentials{})
+	if err != nil || !reflect.DeepEqual(names, []string{"User"}) {
+		t.Errorf("FieldNames() = %v, %v; want [User]", names, err)
+	}
+}
+
 type Date time.Time
 
 func (d *Date) Merge(src reflect.Value) error {
@@ -518,3 +1168,2192 @@ func TestCopyTime(t *testing.T) {
 		t.Fatalf("p1 and p2 are not equal")
 	}
 }
+
+type TaggedAddress struct {
+	Street string
+	City   string `merge:"-"`
+}
+
+type TaggedStruct struct {
+	ID      int `merge:"-"`
+	Name    string
+	Address TaggedAddress
+	Billing TaggedAddress `merge:"-"`
+}
+
+func TestMergeSkipTag(t *testing.T) {
+	dst := TaggedStruct{
+		ID:      1,
+		Name:    "Alice",
+		Address: TaggedAddress{Street: "123 Old St", City: "Old City"},
+		Billing: TaggedAddress{Street: "1 Bill St", City: "Bill City"},
+	}
+
+	src := TaggedStruct{
+		ID:      2,
+		Name:    "Bob",
+		Address: TaggedAddress{Street: "456 New St", City: "New City"},
+		Billing: TaggedAddress{Street: "2 Bill St", City: "New Bill City"},
+	}
+
+	// The tag must win even when the fields are explicitly included.
+	cfg := Config{
+		Option:  IncludeAll,
+		Include: []string{"ID", "Name", "Address.Street", "Address.City", "Billing"},
+	}
+
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TaggedStruct{
+		ID:      1,
+		Name:    "Bob",
+		Address: TaggedAddress{Street: "456 New St", City: "Old City"},
+		Billing: TaggedAddress{Street: "1 Bill St", City: "Bill City"},
+	}
+
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+}
+
+type OmitEmptyStruct struct {
+	Name     string
+	Override string `merge:"omitempty"`
+	Count    int    `merge:"omitempty"`
+}
+
+func TestMergeOmitEmptyTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      OmitEmptyStruct
+		src      OmitEmptyStruct
+		cfg      Config
+		expected OmitEmptyStruct
+	}{
+		{
+			name:     "IncludeAll skips empty tagged fields",
+			dst:      OmitEmptyStruct{Name: "Alice", Override: "keep", Count: 3},
+			src:      OmitEmptyStruct{Name: ""},
+			cfg:      Config{Option: IncludeAll},
+			expected: OmitEmptyStruct{Name: "", Override: "keep", Count: 3},
+		},
+		{
+			name:     "IncludeAll copies non-empty tagged fields",
+			dst:      OmitEmptyStruct{Name: "Alice", Override: "old", Count: 3},
+			src:      OmitEmptyStruct{Name: "Bob", Override: "new", Count: 7},
+			cfg:      Config{Option: IncludeAll},
+			expected: OmitEmptyStruct{Name: "Bob", Override: "new", Count: 7},
+		},
+		{
+			name:     "ExcludeEmpty is unaffected",
+			dst:      OmitEmptyStruct{Name: "Alice", Override: "keep", Count: 3},
+			src:      OmitEmptyStruct{Count: 9},
+			cfg:      Config{Option: ExcludeEmpty},
+			expected: OmitEmptyStruct{Name: "Alice", Override: "keep", Count: 9},
+		},
+		{
+			name:     "OverwriteEmpty still requires a non-empty source",
+			dst:      OmitEmptyStruct{Name: ""},
+			src:      OmitEmptyStruct{Name: "Bob", Override: ""},
+			cfg:      Config{Option: OverwriteEmpty},
+			expected: OmitEmptyStruct{Name: "Bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+type Role struct {
+	Name string
+}
+
+type AppendStruct struct {
+	Tags  []string `merge:"append"`
+	Roles []*Role  `merge:"append"`
+	Notes []string
+}
+
+func TestMergeAppendTag(t *testing.T) {
+	admin := &Role{Name: "admin"}
+	editor := &Role{Name: "editor"}
+
+	tests := []struct {
+		name     string
+		dst      AppendStruct
+		src      AppendStruct
+		expected AppendStruct
+	}{
+		{
+			name:     "Append to existing",
+			dst:      AppendStruct{Tags: []string{"a"}, Roles: []*Role{admin}, Notes: []string{"old"}},
+			src:      AppendStruct{Tags: []string{"b", "c"}, Roles: []*Role{editor}, Notes: []string{"new"}},
+			expected: AppendStruct{Tags: []string{"a", "b", "c"}, Roles: []*Role{admin, editor}, Notes: []string{"new"}},
+		},
+		{
+			name:     "Nil destination",
+			dst:      AppendStruct{},
+			src:      AppendStruct{Tags: []string{"b"}, Roles: []*Role{editor}},
+			expected: AppendStruct{Tags: []string{"b"}, Roles: []*Role{editor}},
+		},
+		{
+			name:     "Nil source",
+			dst:      AppendStruct{Tags: []string{"a"}, Roles: []*Role{admin}, Notes: []string{"old"}},
+			src:      AppendStruct{},
+			expected: AppendStruct{Tags: []string{"a"}, Roles: []*Role{admin}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, Config{Option: IncludeAll}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeAppendNilSlices(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst, src []string
+		expected []string
+	}{
+		{"nil dst and nil src", nil, nil, nil},
+		{"nil dst and empty src", nil, []string{}, nil},
+		{"nil dst and non-nil src", nil, []string{"a"}, []string{"a"}},
+		{"non-nil dst and nil src", []string{"a"}, nil, []string{"a"}},
+		{"non-nil dst and non-nil src", []string{"a"}, []string{"b"}, []string{"a", "b"}},
+	}
+
+	for _, option := range []MergeOption{IncludeAll, ExcludeEmpty} {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				dst := AppendStruct{Tags: tt.dst}
+				if err := Merge(&dst, AppendStruct{Tags: tt.src}, Config{Option: option}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(dst.Tags, tt.expected) {
+					t.Errorf("option %d: Tags = %#v, want %#v", option, dst.Tags, tt.expected)
+				}
+			})
+		}
+	}
+}
+
+type Playlist struct {
+	Name    string
+	Songs   []string
+	Ratings []int
+	Cover   []byte
+	Current []string `merge:"replace"`
+	Tags    []string `merge:"append"`
+	Meta    json.RawMessage
+}
+
+func TestMergeAppendSlices(t *testing.T) {
+	dst := Playlist{
+		Name:    "old",
+		Songs:   []string{"a"},
+		Cover:   []byte("ab"),
+		Current: []string{"a"},
+		Tags:    []string{"x"},
+		Meta:    json.RawMessage(`{"v":1}`),
+	}
+	src := Playlist{
+		Name:    "new",
+		Songs:   []string{"b", "c"},
+		Ratings: []int{5},
+		Cover:   []byte("cd"),
+		Current: []string{"c"},
+		Tags:    []string{"y"},
+		Meta:    json.RawMessage(`{"v":2}`),
+	}
+
+	if err := Merge(&dst, src, Config{Option: AppendSlices}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Playlist{
+		Name:    "new",
+		Songs:   []string{"a", "b", "c"},
+		Ratings: []int{5},
+		Cover:   []byte("abcd"),
+		Current: []string{"c"},
+		Tags:    []string{"x", "y"},
+		Meta:    json.RawMessage(`{"v":2}`),
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+
+	// Scalars are merged as with IncludeAll, and nil source slices leave
+	// the destination slices alone.
+	if err := Merge(&dst, Playlist{}, Config{Option: AppendSlices}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected.Name, expected.Current, expected.Meta = "", nil, nil
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestMustMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     interface{}
+		src     interface{}
+		wantErr error
+	}{
+		{name: "Invalid destination", dst: TestStruct{}, src: TestStruct{}, wantErr: ErrInvalidDestination},
+		{name: "Invalid source", dst: &TestStruct{}, src: &TestStruct{}, wantErr: ErrInvalidSource},
+		{name: "Type mismatch", dst: &TestStruct{}, src: struct{ Foo string }{}, wantErr: ErrTypeMismatch},
+		{name: "Valid", dst: &TestStruct{}, src: TestStruct{Name: "Bob"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if tt.wantErr == nil {
+					if r != nil {
+						t.Fatalf("unexpected panic: %v", r)
+					}
+					return
+				}
+
+				err, ok := r.(*MergeError)
+				if !ok {
+					t.Fatalf("expected panic with *MergeError, got %#v", r)
+				}
+				if err != tt.wantErr {
+					t.Errorf("panic = %v, want %v", err, tt.wantErr)
+				}
+			}()
+
+			MustMerge(tt.dst, tt.src)
+		})
+	}
+}
+
+func TestMergeMany(t *testing.T) {
+	base := TestStruct{Name: "Base", Age: 20, Address: Address{City: "Base City"}}
+	env := TestStruct{Age: 30, Address: Address{Country: "Uganda"}}
+	patch := TestStruct{Name: "Patch"}
+
+	var dst TestStruct
+	err := MergeMany(&dst, []interface{}{base, env, patch}, Config{Option: ExcludeEmpty})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{
+		Name:    "Patch",
+		Age:     30,
+		Address: Address{City: "Base City", Country: "Uganda"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("MergeMany() = %#v, want %#v", dst, expected)
+	}
+
+	// IncludeAll lets the last source win outright.
+	dst = TestStruct{}
+	if err := MergeMany(&dst, []interface{}{base, env}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, env) {
+		t.Errorf("MergeMany() = %#v, want %#v", dst, env)
+	}
+}
+
+func TestMergeManyStopsOnError(t *testing.T) {
+	dst := TestStruct{Name: "Alice"}
+	srcs := []interface{}{
+		TestStruct{Name: "Bob"},
+		struct{ Foo string }{},
+		TestStruct{Name: "Carol"},
+	}
+
+	err := MergeMany(&dst, srcs)
+	if err != ErrTypeMismatch {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+	if dst.Name != "Bob" {
+		t.Errorf("expected merge to stop after first source, got %q", dst.Name)
+	}
+}
+
+func TestMergeWithContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dst := TestStruct{Name: "Alice"}
+	err := MergeWithContext(ctx, &dst, TestStruct{Name: "Bob"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if dst.Name != "Alice" {
+		t.Errorf("expected dst to be untouched, got %q", dst.Name)
+	}
+}
+
+// slowField is a Merger that takes a while to merge.
+type slowField struct {
+	Value string
+}
+
+func (s *slowField) Merge(src reflect.Value) error {
+	time.Sleep(20 * time.Millisecond)
+	s.Value = src.Interface().(slowField).Value
+	return nil
+}
+
+type prefixKey struct{}
+
+// enrichedField is a MergerWithContext reading a prefix from the context.
+// Its Merge method must not be called.
+type enrichedField struct {
+	Value string
+}
+
+func (e *enrichedField) Merge(src reflect.Value) error {
+	return errors.New("Merge called instead of MergeCtx")
+}
+
+func (e *enrichedField) MergeCtx(ctx context.Context, src reflect.Value) error {
+	prefix, _ := ctx.Value(prefixKey{}).(string)
+	e.Value = prefix + src.Interface().(enrichedField).Value
+	return nil
+}
+
+// cancelField is a Merger that cancels the merge.
+type cancelField struct {
+	cancel context.CancelFunc
+}
+
+func (c *cancelField) Merge(src reflect.Value) error {
+	src.Interface().(cancelField).cancel()
+	return nil
+}
+
+type Enriched struct {
+	Stop  cancelField
+	Owner enrichedField
+}
+
+func TestMergerWithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), prefixKey{}, "user:")
+	var dst Enriched
+	src := Enriched{Stop: cancelField{cancel: func() {}}, Owner: enrichedField{Value: "42"}}
+	if err := MergeWithContext(ctx, &dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Owner.Value != "user:42" {
+		t.Errorf("Owner = %q, want user:42", dst.Owner.Value)
+	}
+
+	// MergeCtx is not called once the context is cancelled.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	dst = Enriched{}
+	src.Stop.cancel = cancel
+	if err := MergeWithContext(ctx, &dst, src); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if dst.Owner.Value != "" {
+		t.Errorf("MergeCtx was called after cancellation: %q", dst.Owner.Value)
+	}
+}
+
+type DeepStruct struct {
+	Slow  slowField
+	Inner struct {
+		Name  string
+		Inner struct {
+			Name string
+		}
+	}
+}
+
+func TestMergeWithContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	var dst, src DeepStruct
+	src.Slow.Value = "slow"
+	src.Inner.Name = "inner"
+	src.Inner.Inner.Name = "innermost"
+
+	err := MergeWithContext(ctx, &dst, src)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Fields before the deadline are merged, nested ones after it are not.
+	if dst.Slow.Value != "slow" {
+		t.Errorf("expected Slow to be merged, got %q", dst.Slow.Value)
+	}
+	if dst.Inner.Name != "" || dst.Inner.Inner.Name != "" {
+		t.Errorf("expected nested fields to be left unmerged, got %#v", dst.Inner)
+	}
+
+	if err := MergeWithContext(context.Background(), &dst, src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, src) {
+		t.Errorf("expected %#v, got %#v", src, dst)
+	}
+}
+
+type Level3 struct {
+	Value string
+	Other string
+}
+
+type Level2 struct {
+	Level3 Level3
+	Name   string
+}
+
+type Level1 struct {
+	Level2 Level2
+	Name   string
+}
+
+func TestMergeIncludeDeepPaths(t *testing.T) {
+	dst := Level1{Name: "old", Level2: Level2{Name: "old", Level3: Level3{Value: "old", Other: "old"}}}
+	src := Level1{Name: "new", Level2: Level2{Name: "new", Level3: Level3{Value: "new", Other: "new"}}}
+
+	tests := []struct {
+		name     string
+		include  []string
+		expected Level1
+	}{
+		{
+			name:     "Leaf three levels deep",
+			include:  []string{"Level2.Level3.Value"},
+			expected: Level1{Name: "old", Level2: Level2{Name: "old", Level3: Level3{Value: "new", Other: "old"}}},
+		},
+		{
+			name:     "Whole nested struct",
+			include:  []string{"Level2.Level3"},
+			expected: Level1{Name: "old", Level2: Level2{Name: "old", Level3: Level3{Value: "new", Other: "new"}}},
+		},
+		{
+			name:     "Name prefix is not a parent",
+			include:  []string{"Level2Name", "Na"},
+			expected: dst,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, Config{Include: tt.include}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeExcludeFunc(t *testing.T) {
+	type Order struct {
+		ID         int
+		CustomerID int
+		Total      float64
+		Shipping   Address
+		Billing    Address
+	}
+
+	src := Order{ID: 2, CustomerID: 20, Total: 9.5, Shipping: Address{City: "Gulu"}, Billing: Address{City: "Lira"}}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected Order
+	}{
+		{
+			name:     "Suffix",
+			cfg:      Config{ExcludeFunc: func(p string) bool { return strings.HasSuffix(p, "ID") }},
+			expected: Order{ID: 1, CustomerID: 10, Total: 9.5, Shipping: Address{City: "Gulu"}, Billing: Address{City: "Lira"}},
+		},
+		{
+			name: "Nested struct and static Exclude",
+			cfg: Config{
+				Exclude:     []string{"ID"},
+				ExcludeFunc: func(p string) bool { return p == "Billing" || p == "Shipping.City" },
+			},
+			expected: Order{ID: 1, CustomerID: 20, Total: 9.5, Billing: Address{City: "Kampala"}},
+		},
+		{
+			name: "Only called for included fields",
+			cfg: Config{
+				Include: []string{"Total"},
+				ExcludeFunc: func(p string) bool {
+					if p != "Total" {
+						t.Errorf("ExcludeFunc called with %q", p)
+					}
+					return false
+				},
+			},
+			expected: Order{ID: 1, CustomerID: 10, Total: 9.5, Billing: Address{City: "Kampala"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := Order{ID: 1, CustomerID: 10, Billing: Address{City: "Kampala"}}
+			if err := Merge(&dst, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst != tt.expected {
+				t.Errorf("Merge() = %+v, want %+v", dst, tt.expected)
+			}
+		})
+	}
+}
+
+type Billing struct {
+	Card   string `merge:"group=pii"`
+	Amount int
+}
+
+type Patient struct {
+	Name      string `merge:"group=pii"`
+	Email     string `merge:"group=pii,audit"`
+	Diagnosis string
+	UpdatedBy string  `merge:"group=audit,omitempty"`
+	Contact   Address `merge:"group=pii"`
+	Billing   Billing
+}
+
+func TestMergeGroups(t *testing.T) {
+	dst := Patient{Name: "alice", Email: "a@x.io", Diagnosis: "flu", UpdatedBy: "bob", Billing: Billing{Card: "1111", Amount: 10}}
+	src := Patient{
+		Name: "alicia", Email: "alicia@x.io", Diagnosis: "cold",
+		Contact: Address{City: "Kampala"}, Billing: Billing{Card: "2222", Amount: 20},
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want Patient
+	}{
+		{
+			name: "pii",
+			cfg:  Config{Groups: []string{"pii"}},
+			want: Patient{Name: "alicia", Email: "alicia@x.io", Diagnosis: "flu", UpdatedBy: "bob", Contact: Address{City: "Kampala"}, Billing: Billing{Card: "2222", Amount: 10}},
+		},
+		{
+			// UpdatedBy keeps its omitempty option.
+			name: "audit",
+			cfg:  Config{Groups: []string{"audit"}},
+			want: Patient{Name: "alice", Email: "alicia@x.io", Diagnosis: "flu", UpdatedBy: "bob", Billing: Billing{Card: "1111", Amount: 10}},
+		},
+		{
+			name: "with include and exclude",
+			cfg:  Config{Groups: []string{"pii"}, Include: []string{"Diagnosis"}, Exclude: []string{"Contact", "Billing.Card"}},
+			want: Patient{Name: "alicia", Email: "alicia@x.io", Diagnosis: "cold", UpdatedBy: "bob", Billing: Billing{Card: "1111", Amount: 10}},
+		},
+		{
+			name: "unknown group",
+			cfg:  Config{Groups: []string{"billing"}},
+			want: dst,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if err := ValidateConfig(Config{Groups: []string{"pii"}, Exclude: []string{"Email"}}, Patient{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateConfig(Config{Groups: []string{"pii"}, IncludeFunc: func(string) bool { return true }}, Patient{}); !errors.Is(err, ErrConflictingPaths) {
+		t.Errorf("expected ErrConflictingPaths, got %v", err)
+	}
+}
+
+type Sec struct {
+	Token string `merge:"group=sec"`
+	Note  string
+	Next  *Sec
+}
+
+type Vault struct {
+	Val Sec
+	Ptr *Sec
+}
+
+func TestMergeGroupsThroughPointers(t *testing.T) {
+	dst := Vault{Val: Sec{Token: "a", Note: "a"}, Ptr: &Sec{Token: "a", Note: "a"}}
+	src := Vault{Val: Sec{Token: "b", Note: "b"}, Ptr: &Sec{Token: "b", Note: "b", Next: &Sec{Token: "c"}}}
+
+	if err := Merge(&dst, src, Config{Groups: []string{"sec"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Vault{Val: Sec{Token: "b", Note: "a"}, Ptr: &Sec{Token: "b", Note: "a"}}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("got %+v %+v, want %+v %+v", dst, dst.Ptr, expected, expected.Ptr)
+	}
+}
+
+func TestMergeIncludeFunc(t *testing.T) {
+	type Order struct {
+		ID         int
+		CustomerID int
+		Total      float64
+		Shipping   Address
+		Billing    Address
+		Secret     string `merge:"-"`
+	}
+
+	src := Order{ID: 2, CustomerID: 20, Total: 9.5, Shipping: Address{Street: "Main St", City: "Gulu"}, Billing: Address{City: "Lira"}, Secret: "x"}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected Order
+	}{
+		{
+			name:     "Suffix",
+			cfg:      Config{IncludeFunc: func(p string) bool { return strings.HasSuffix(p, "ID") }},
+			expected: Order{ID: 2, CustomerID: 20, Billing: Address{City: "Kampala"}},
+		},
+		{
+			name:     "Parent selects nested fields",
+			cfg:      Config{IncludeFunc: func(p string) bool { return p == "Shipping" }},
+			expected: Order{ID: 1, CustomerID: 10, Shipping: Address{Street: "Main St", City: "Gulu"}, Billing: Address{City: "Kampala"}},
+		},
+		{
+			name:     "Nested field only",
+			cfg:      Config{IncludeFunc: func(p string) bool { return p == "Shipping.City" || p == "Secret" }},
+			expected: Order{ID: 1, CustomerID: 10, Shipping: Address{City: "Gulu"}, Billing: Address{City: "Kampala"}},
+		},
+		{
+			name: "Overrides Include",
+			cfg: Config{
+				Include:     []string{"Total"},
+				IncludeFunc: func(p string) bool { return p == "ID" },
+			},
+			expected: Order{ID: 2, CustomerID: 10, Billing: Address{City: "Kampala"}},
+		},
+		{
+			name: "Exclude takes precedence",
+			cfg: Config{
+				Exclude:     []string{"Shipping.City"},
+				ExcludeFunc: func(p string) bool { return p == "ID" },
+				IncludeFunc: func(p string) bool { return p == "ID" || p == "Shipping" },
+			},
+			expected: Order{ID: 1, CustomerID: 10, Shipping: Address{Street: "Main St"}, Billing: Address{City: "Kampala"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := Order{ID: 1, CustomerID: 10, Billing: Address{City: "Kampala"}}
+			if err := Merge(&dst, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dst != tt.expected {
+				t.Errorf("Merge() = %+v, want %+v", dst, tt.expected)
+			}
+		})
+	}
+
+	err := ValidateConfig(Config{Include: []string{"Total"}, IncludeFunc: func(string) bool { return true }}, Order{})
+	if !errors.Is(err, ErrConflictingPaths) {
+		t.Errorf("expected ErrConflictingPaths, got %v", err)
+	}
+	if err := ValidateConfig(Config{IncludeFunc: func(string) bool { return true }}, Order{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMergeTransformers(t *testing.T) {
+	dst := TestStruct{Name: "alice", Age: 30, Address: Address{City: "kampala"}, Count: 3}
+	src := TestStruct{Name: "bob", Age: 150, Address: Address{City: "entebbe"}, Count: 8}
+
+	cfg := Config{
+		Option: IncludeAll,
+		Transformers: map[string]func(dst, src reflect.Value) reflect.Value{
+			// Uppercase the incoming name.
+			"Name": func(dst, src reflect.Value) reflect.Value {
+				return reflect.ValueOf(strings.ToUpper(src.String()))
+			},
+			// Clamp the age to 120.
+			"Age": func(dst, src reflect.Value) reflect.Value {
+				if src.Int() > 120 {
+					return reflect.ValueOf(120)
+				}
+				return src
+			},
+			// Keep the destination city.
+			"Address.City": func(dst, src reflect.Value) reflect.Value {
+				return dst
+			},
+		},
+	}
+
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Name: "BOB", Age: 120, Address: Address{City: "kampala"}, Count: 8}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestMergeTransformByKind(t *testing.T) {
+	dst := TestStruct{Name: "alice", Age: 30, Address: Address{Street: "main", City: "kampala"}}
+	src := TestStruct{Name: "  bob ", Age: 150, Address: Address{Street: "   ", City: " entebbe"}, Count: 8}
+
+	cfg := Config{
+		Option: ExcludeEmpty,
+		Transform: map[reflect.Kind]func(reflect.Value) reflect.Value{
+			// Trimmed strings are checked for emptiness.
+			reflect.String: func(v reflect.Value) reflect.Value {
+				return reflect.ValueOf(strings.TrimSpace(v.String()))
+			},
+			reflect.Int: func(v reflect.Value) reflect.Value {
+				if v.Int() > 120 {
+					return reflect.ValueOf(120)
+				}
+				return v
+			},
+		},
+		// Field transformers take precedence.
+		Transformers: map[string]func(dst, src reflect.Value) reflect.Value{
+			"Name": func(dst, src reflect.Value) reflect.Value {
+				return reflect.ValueOf(strings.ToUpper(src.String()))
+			},
+		},
+	}
+
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Name: "  BOB ", Age: 120, Address: Address{Street: "main", City: "entebbe"}, Count: 8}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+}
+
+func TestMergeOnFieldSet(t *testing.T) {
+	dst := TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Kampala"}, Count: 1}
+	src := TestStruct{Name: "Bob", Age: 30, Address: Address{City: "Entebbe", Country: "Uganda"}}
+
+	type change struct {
+		old, new interface{}
+	}
+	changes := map[string]change{}
+
+	cfg := Config{
+		Option:  ExcludeEmpty,
+		Exclude: []string{"Address.Country"},
+		OnFieldSet: func(path string, oldVal, newVal reflect.Value) {
+			changes[path] = change{oldVal.Interface(), newVal.Interface()}
+		},
+	}
+
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Age is unchanged, Count is skipped as empty and Country is excluded.
+	expected := map[string]change{
+		"Name":         {"Alice", "Bob"},
+		"Address.City": {"Kampala", "Entebbe"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("OnFieldSet calls = %v, want %v", changes, expected)
+	}
+}
+
+func TestMergeOverwriteNonEmpty(t *testing.T) {
+	dst := TestStruct{
+		Name:    "Alice",
+		Age:     0,
+		Address: Address{Street: "123 Old St"},
+		Active:  true,
+		Count:   0,
+	}
+	src := TestStruct{
+		Name:    "Bob",
+		Age:     25,
+		Address: Address{Street: "456 New St", City: "New City"},
+		Active:  false,
+		Count:   5,
+	}
+
+	if err := Merge(&dst, src, Config{Option: OverwriteNonEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{
+		Name:    "Bob",
+		Age:     0,
+		Address: Address{Street: "456 New St"},
+		Active:  false,
+		Count:   0,
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+}
+
+type KeepFirstStruct struct {
+	Name    string
+	Enabled bool
+	Tags    []string
+	Limits  map[string]int
+}
+
+func TestMergeKeepFirst(t *testing.T) {
+	defaults := KeepFirstStruct{
+		Name:    "default",
+		Enabled: true,
+		Tags:    []string{"default"},
+		Limits:  map[string]int{"cpu": 1},
+	}
+
+	tests := []struct {
+		name     string
+		dst      KeepFirstStruct
+		expected KeepFirstStruct
+	}{
+		{
+			name:     "Unset destination takes the source",
+			dst:      KeepFirstStruct{},
+			expected: defaults,
+		},
+		{
+			name: "Set destination is kept",
+			dst: KeepFirstStruct{
+				Name:   "custom",
+				Tags:   []string{},
+				Limits: map[string]int{},
+			},
+			expected: KeepFirstStruct{
+				Name:    "custom",
+				Enabled: true, // false is indistinguishable from unset
+				Tags:    []string{},
+				Limits:  map[string]int{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, defaults, Config{Option: KeepFirst}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", tt.dst, tt.expected)
+			}
+		})
+	}
+
+	// A true bool is set and survives a later merge with false.
+	dst := KeepFirstStruct{Enabled: true}
+	if err := Merge(&dst, KeepFirstStruct{}, Config{Option: KeepFirst}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dst.Enabled {
+		t.Error("expected Enabled to be kept")
+	}
+}
+
+type ServerConfig struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+	Debug   bool
+	Tags    []string
+	TLS     Address
+}
+
+func TestMergeWithDefaults(t *testing.T) {
+	defaults := ServerConfig{
+		Host:    "localhost",
+		Port:    8080,
+		Timeout: 30 * time.Second,
+		Debug:   true,
+		Tags:    []string{"default"},
+		TLS:     Address{City: "Kampala", Country: "Uganda"},
+	}
+
+	tests := []struct {
+		name     string
+		dst      ServerConfig
+		cfg      []Config
+		expected ServerConfig
+	}{
+		{
+			name: "Empty fields are filled",
+			dst:  ServerConfig{Port: 9090, TLS: Address{City: "Gulu"}},
+			expected: ServerConfig{
+				Host:    "localhost",
+				Port:    9090,
+				Timeout: 30 * time.Second,
+				Tags:    []string{"default"},
+				TLS:     Address{City: "Gulu", Country: "Uganda"},
+			},
+		},
+		{
+			name: "False is kept",
+			dst:  ServerConfig{Host: "example.com"},
+			expected: ServerConfig{
+				Host:    "example.com",
+				Port:    8080,
+				Timeout: 30 * time.Second,
+				Tags:    []string{"default"},
+				TLS:     Address{City: "Kampala", Country: "Uganda"},
+			},
+		},
+		{
+			name:     "TreatBoolFalseAsZero",
+			dst:      ServerConfig{},
+			cfg:      []Config{{TreatBoolFalseAsZero: true}},
+			expected: defaults,
+		},
+		{
+			name: "Option is ignored",
+			dst:  ServerConfig{Host: "example.com", Debug: true},
+			cfg:  []Config{{Option: IncludeAll}},
+			expected: ServerConfig{
+				Host:    "example.com",
+				Port:    8080,
+				Timeout: 30 * time.Second,
+				Debug:   true,
+				Tags:    []string{"default"},
+				TLS:     Address{City: "Kampala", Country: "Uganda"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := MergeWithDefaults(&tt.dst, defaults, tt.cfg...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("MergeWithDefaults() = %#v, want %#v", tt.dst, tt.expected)
+			}
+		})
+	}
+
+	// Empty defaults never clear destination fields.
+	dst := ServerConfig{Host: "example.com", Port: 9090, Debug: true}
+	want := dst
+	if err := MergeWithDefaults(&dst, ServerConfig{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("MergeWithDefaults() = %#v, want %#v", dst, want)
+	}
+}
+
+func TestMergeOptionMatrix(t *testing.T) {
+	dst := TestStruct{Name: "dst", Address: Address{City: "Kampala"}, Count: 1}
+	src := TestStruct{Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}, Active: true, Count: 2}
+
+	tests := []struct {
+		option   MergeOption
+		expected TestStruct
+	}{
+		{
+			option:   IncludeAll,
+			expected: TestStruct{Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}, Active: true, Count: 2},
+		},
+		{
+			option:   ExcludeEmpty,
+			expected: TestStruct{Name: "dst", Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}, Active: true, Count: 2},
+		},
+		{
+			option:   OverwriteEmpty,
+			expected: TestStruct{Name: "dst", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}, Active: true, Count: 1},
+		},
+		{
+			option:   ExcludeZeroInDst,
+			expected: TestStruct{Name: "dst", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}, Active: true, Count: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		got := dst
+		if err := Merge(&got, src, Config{Option: tt.option}); err != nil {
+			t.Fatalf("option %d: unexpected error: %v", tt.option, err)
+		}
+		if got != tt.expected {
+			t.Errorf("option %d: got %+v, want %+v", tt.option, got, tt.expected)
+		}
+	}
+
+	// OverwriteEmpty also writes empty source values into empty
+	// destination fields, while ExcludeZeroInDst skips them.
+	for option, skipped := range map[MergeOption][]string{
+		OverwriteEmpty:   {"Name", "Address.City", "Count"},
+		ExcludeZeroInDst: {"Name", "Address.Street", "Address.City", "Count"},
+	} {
+		got := dst
+		result, err := MergeWithResult(&got, TestStruct{Age: 30, Address: Address{City: "Gulu"}}, Config{Option: option, Include: []string{"Name", "Address.City", "Address.Street", "Count"}})
+		if err != nil {
+			t.Fatalf("option %d: unexpected error: %v", option, err)
+		}
+		if !reflect.DeepEqual(result.Skipped, skipped) {
+			t.Errorf("option %d: skipped %v, want %v", option, result.Skipped, skipped)
+		}
+	}
+}
+
+func TestMergeOptionString(t *testing.T) {
+	tests := []struct {
+		option   MergeOption
+		expected string
+	}{
+		{IncludeAll, "IncludeAll"},
+		{SourcePriority, "IncludeAll"},
+		{DestinationPriority, "KeepFirst"},
+		{ExcludeZeroInDst, "ExcludeZeroInDst"},
+		{SymmetricDifference, "SymmetricDifference"},
+		{MergeOption(-1), "MergeOption(-1)"},
+		{MergeOption(99), "MergeOption(99)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.option.String(); got != tt.expected {
+			t.Errorf("MergeOption(%d).String() = %q, want %q", int(tt.option), got, tt.expected)
+		}
+	}
+
+	// The aliases behave like the options they stand for.
+	dst := TestStruct{Name: "dst"}
+	if err := Merge(&dst, TestStruct{Name: "src", Age: 3}, Config{Option: DestinationPriority}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Name != "dst" || dst.Age != 3 {
+		t.Errorf("DestinationPriority: got %+v", dst)
+	}
+}
+
+func TestMergeSymmetricDifference(t *testing.T) {
+	// Name: both zero, Age: both set, Count: only src set, Active: only dst set.
+	dst := TestStruct{Age: 40, Active: true}
+	src := TestStruct{Age: 30, Count: 2}
+
+	result, err := MergeWithResult(&dst, src, Config{Option: SymmetricDifference, Include: []string{"Name", "Age", "Count", "Active"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Age: 40, Active: true, Count: 2}
+	if dst != expected {
+		t.Errorf("got %+v, want %+v", dst, expected)
+	}
+	if !reflect.DeepEqual(result.Changed, []string{"Count"}) {
+		t.Errorf("Changed = %v, want [Count]", result.Changed)
+	}
+	if !reflect.DeepEqual(result.Skipped, []string{"Name", "Age", "Active"}) {
+		t.Errorf("Skipped = %v, want [Name Age Active]", result.Skipped)
+	}
+}
+
+func TestMergeIntersectionOnly(t *testing.T) {
+	// Name: both set, Age: only src set, Count: only dst set, Street: neither.
+	dst := TestStruct{Name: "alice", Count: 3, Address: Address{City: "kampala"}}
+	src := TestStruct{Name: "bob", Age: 30, Address: Address{City: "entebbe", Country: "uganda"}}
+
+	result, err := MergeWithResult(&dst, src, Config{Option: IntersectionOnly})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Name: "bob", Count: 3, Address: Address{City: "entebbe"}}
+	if dst != expected {
+		t.Errorf("got %+v, want %+v", dst, expected)
+	}
+	if !reflect.DeepEqual(result.Changed, []string{"Name", "Address.City"}) {
+		t.Errorf("Changed = %v, want [Name Address.City]", result.Changed)
+	}
+
+	// KeepFalseBools makes a false destination bool count as set.
+	flags := TestStruct{Active: false}
+	if err := Merge(&flags, TestStruct{Active: true}, Config{Option: IntersectionOnly, KeepFalseBools: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flags.Active {
+		t.Errorf("Active = false, want true with KeepFalseBools")
+	}
+
+	if IntersectionOnly.String() != "IntersectionOnly" {
+		t.Errorf("String() = %q", IntersectionOnly.String())
+	}
+}
+
+type MapStruct struct {
+	Labels    map[string]string
+	Counts    map[string]int
+	Addresses map[string]Address
+}
+
+func TestMergeMapStrategies(t *testing.T) {
+	newDst := func() MapStruct {
+		return MapStruct{
+			Labels: map[string]string{"env": "prod", "team": "core"},
+			Counts: map[string]int{"a": 1},
+			Addresses: map[string]Address{
+				"home": {Street: "1 Home St", City: "Kampala"},
+			},
+		}
+	}
+
+	src := MapStruct{
+		Labels: map[string]string{"env": "dev", "region": "eu"},
+		Counts: map[string]int{"a": 5, "b": 2},
+		Addresses: map[string]Address{
+			"home": {City: "Entebbe", Country: "Uganda"},
+			"work": {Street: "2 Work St"},
+		},
+	}
+
+	tests := []struct {
+		strategy MapMergeStrategy
+		option   MergeOption
+		expected MapStruct
+	}{
+		{
+			strategy: MapReplace,
+			expected: src,
+		},
+		{
+			strategy: MapMergeKeys,
+			expected: MapStruct{
+				Labels: map[string]string{"env": "prod", "team": "core", "region": "eu"},
+				Counts: map[string]int{"a": 1, "b": 2},
+				Addresses: map[string]Address{
+					"home": {Street: "1 Home St", City: "Kampala"},
+					"work": {Street: "2 Work St"},
+				},
+			},
+		},
+		{
+			strategy: MapMergeDeep,
+			expected: MapStruct{
+				Labels: map[string]string{"env": "dev", "team": "core", "region": "eu"},
+				Counts: map[string]int{"a": 5, "b": 2},
+				Addresses: map[string]Address{
+					"home": {City: "Entebbe", Country: "Uganda"},
+					"work": {Street: "2 Work St"},
+				},
+			},
+		},
+		{
+			strategy: MapMergeDeep,
+			option:   ExcludeEmpty,
+			expected: MapStruct{
+				Labels: map[string]string{"env": "dev", "team": "core", "region": "eu"},
+				Counts: map[string]int{"a": 5, "b": 2},
+				Addresses: map[string]Address{
+					"home": {Street: "1 Home St", City: "Entebbe", Country: "Uganda"},
+					"work": {Street: "2 Work St"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("strategy=%d/option=%d", tt.strategy, tt.option), func(t *testing.T) {
+			dst := newDst()
+			original := dst.Labels
+
+			cfg := Config{Option: tt.option, MapMergeStrategy: tt.strategy}
+			if err := Merge(&dst, src, cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", dst, tt.expected)
+			}
+			if len(original) != 2 {
+				t.Errorf("the original destination map was modified: %v", original)
+			}
+		})
+	}
+}
+
+type Settings struct {
+	Values map[string]interface{}
+	Groups map[string]map[string]int
+}
+
+func TestMergeMapDeepInterfaceValues(t *testing.T) {
+	dst := Settings{
+		Values: map[string]interface{}{
+			"address": Address{Street: "1 Home St", City: "Kampala"},
+			"owner":   &Person{Name: "Alice", Age: 30},
+			"limits":  map[string]interface{}{"cpu": 1, "mem": 512},
+			"name":    "old",
+			"mixed":   Address{City: "Gulu"},
+		},
+		Groups: map[string]map[string]int{"a": {"x": 1}},
+	}
+	src := Settings{
+		Values: map[string]interface{}{
+			"address": Address{City: "Entebbe", Country: "Uganda"},
+			"owner":   &Person{Age: 31},
+			"limits":  map[string]interface{}{"mem": 1024, "disk": 10},
+			"name":    "new",
+			"mixed":   "replaced",
+		},
+		Groups: map[string]map[string]int{"a": {"y": 2}, "b": {"z": 3}},
+	}
+	shared := dst.Values["owner"].(*Person)
+
+	cfg := Config{Option: ExcludeEmpty, MapMergeStrategy: MapMergeDeep}
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Settings{
+		Values: map[string]interface{}{
+			"address": Address{Street: "1 Home St", City: "Entebbe", Country: "Uganda"},
+			"owner":   &Person{Name: "Alice", Age: 31},
+			"limits":  map[string]interface{}{"cpu": 1, "mem": 1024, "disk": 10},
+			"name":    "new",
+			"mixed":   "replaced",
+		},
+		Groups: map[string]map[string]int{"a": {"x": 1, "y": 2}, "b": {"z": 3}},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %#v, want %#v", dst, expected)
+	}
+	if shared.Age != 30 {
+		t.Error("merging modified a struct shared with the original map")
+	}
+
+	// Maps that contain themselves are reported.
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	other := map[string]interface{}{}
+	other["self"] = other
+	err := Merge(&Settings{Values: other}, Settings{Values: cyclic}, cfg)
+	if !errors.Is(err, ErrCyclicReference) {
+		t.Errorf("expected ErrCyclicReference, got %v", err)
+	}
+}
+
+func TestMergeNilDestinationMap(t *testing.T) {
+	for _, strategy := range []MapMergeStrategy{MapReplace, MapMergeKeys, MapMergeDeep} {
+		src := MapStruct{
+			Labels:    map[string]string{"env": "prod"},
+			Addresses: map[string]Address{"home": {City: "Kampala"}},
+		}
+
+		var dst MapStruct
+		if err := Merge(&dst, src, Config{MapMergeStrategy: strategy}); err != nil {
+			t.Fatalf("strategy %d: unexpected error: %v", strategy, err)
+		}
+		if !reflect.DeepEqual(dst, src) {
+			t.Errorf("strategy %d: Merge() = %+v, want %+v", strategy, dst, src)
+		}
+		if dst.Counts != nil {
+			t.Errorf("strategy %d: expected Counts to stay nil, got %v", strategy, dst.Counts)
+		}
+
+		// Merging strategies write to a map of their own.
+		dst.Labels["env"] = "dev"
+		if strategy != MapReplace && src.Labels["env"] != "prod" {
+			t.Errorf("strategy %d: destination map shared with source", strategy)
+		}
+	}
+}
+
+func TestMergeMapDeepExclude(t *testing.T) {
+	dst := MapStruct{Addresses: map[string]Address{"home": {City: "Kampala"}}}
+	src := MapStruct{Addresses: map[string]Address{"home": {Street: "1 Home St", City: "Entebbe"}}}
+
+	cfg := Config{MapMergeStrategy: MapMergeDeep, Exclude: []string{"Addresses.home.City"}}
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Address{Street: "1 Home St", City: "Kampala"}
+	if dst.Addresses["home"] != expected {
+		t.Errorf("expected %#v, got %#v", expected, dst.Addresses["home"])
+	}
+}
+
+var errBadVersion = errors.New("bad version")
+
+// Version is a Merger that rejects downgrades.
+type Version int
+
+func (v *Version) Merge(src reflect.Value) error {
+	next := Version(src.Int())
+	if next < *v {
+		return errBadVersion
+	}
+	*v = next
+	return nil
+}
+
+// Checksum is a struct-level Merger that always fails.
+type Checksum struct {
+	Sum string
+}
+
+func (c *Checksum) Merge(src reflect.Value) error {
+	return ErrTypeMismatch
+}
+
+type Document struct {
+	Meta struct {
+		Version  Version
+		Checksum Checksum
+	}
+}
+
+func TestMergeErrorCodes(t *testing.T) {
+	if ErrInvalidDestination.Error() != "destination must be a pointer to a struct" {
+		t.Errorf("unexpected message %q", ErrInvalidDestination)
+	}
+
+	cause := errors.New("boom")
+	err := &MergeError{Code: ErrCodeInvalidPath, Path: "Address.Stret", Cause: cause}
+	if got, want := err.Error(), "Address.Stret: field path does not exist: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, ErrInvalidPath) || errors.Is(err, ErrTypeMismatch) {
+		t.Error("expected errors.Is to compare codes")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected errors.Is to find the cause")
+	}
+
+	// errors.As extracts the code and path from errors wrapped in a FieldError.
+	verr := ValidateConfig(Config{Include: []string{"Address.Stret"}}, TestStruct{})
+	var mergeErr *MergeError
+	if !errors.As(verr, &mergeErr) {
+		t.Fatalf("expected a *MergeError, got %#v", verr)
+	}
+	if mergeErr.Code != ErrCodeInvalidPath || mergeErr.Path != "Address.Stret" {
+		t.Errorf("unexpected error %#v", mergeErr)
+	}
+	if ErrInvalidPath.Path != "" {
+		t.Error("errors.As modified the sentinel")
+	}
+
+	// Errors that are not MergeErrors are not extracted.
+	var dst, src Document
+	dst.Meta.Version = 2
+	src.Meta.Version = 1
+	if err := Merge(&dst, src); errors.As(err, &mergeErr) {
+		t.Errorf("unexpected *MergeError from %v", err)
+	}
+}
+
+func TestMergeFieldError(t *testing.T) {
+	var dst, src Document
+	dst.Meta.Version = 2
+	src.Meta.Version = 1
+
+	err := Merge(&dst, src, Config{Exclude: []string{"Meta.Checksum"}})
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("expected a *FieldError, got %#v", err)
+	}
+	if fieldErr.Path != "Meta.Version" {
+		t.Errorf("expected path Meta.Version, got %q", fieldErr.Path)
+	}
+	if !errors.Is(err, errBadVersion) {
+		t.Errorf("expected errors.Is to find the Merger error, got %v", err)
+	}
+
+	src.Meta.Version = 3
+	err = Merge(&dst, src)
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+	if !errors.As(err, &fieldErr) || fieldErr.Path != "Meta.Checksum" {
+		t.Errorf("expected path Meta.Checksum, got %v", err)
+	}
+	if err.Error() != "Meta.Checksum: source and destination types do not match" {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+type JSONAddress struct {
+	Street     string `json:"street"`
+	PostalCode string `json:"postal_code,omitempty"`
+}
+
+type JSONUser struct {
+	FullName string      `json:"full_name"`
+	Email    string      `json:"-"`
+	Address  JSONAddress `json:"address"`
+}
+
+func TestMergeUseJSONTags(t *testing.T) {
+	dst := JSONUser{FullName: "Alice", Email: "a@x.io", Address: JSONAddress{Street: "Old St", PostalCode: "111"}}
+	src := JSONUser{FullName: "Bob", Email: "b@x.io", Address: JSONAddress{Street: "New St", PostalCode: "222"}}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected JSONUser
+	}{
+		{
+			name: "Include by JSON name",
+			cfg:  Config{UseJSONTags: true, Include: []string{"address.postal_code"}},
+			expected: JSONUser{FullName: "Alice", Email: "a@x.io",
+				Address: JSONAddress{Street: "Old St", PostalCode: "222"}},
+		},
+		{
+			name: "Go names still work",
+			cfg:  Config{UseJSONTags: true, Include: []string{"Address.Street", "full_name"}},
+			expected: JSONUser{FullName: "Bob", Email: "a@x.io",
+				Address: JSONAddress{Street: "New St", PostalCode: "111"}},
+		},
+		{
+			name: "Exclude by JSON name",
+			cfg:  Config{UseJSONTags: true, Exclude: []string{"address", "full_name"}},
+			expected: JSONUser{FullName: "Alice", Email: "b@x.io",
+				Address: JSONAddress{Street: "Old St", PostalCode: "111"}},
+		},
+		{
+			name:     "JSON names ignored without UseJSONTags",
+			cfg:      Config{Include: []string{"address.postal_code"}},
+			expected: dst,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeFieldRenamer(t *testing.T) {
+	dst := JSONUser{FullName: "Alice", Email: "a@x.io", Address: JSONAddress{Street: "Old St", PostalCode: "111"}}
+	src := JSONUser{FullName: "Bob", Email: "b@x.io", Address: JSONAddress{Street: "New St", PostalCode: "222"}}
+
+	snake := func(name string) string {
+		var b strings.Builder
+		for i, r := range name {
+			if unicode.IsUpper(r) {
+				if i > 0 {
+					b.WriteByte('_')
+				}
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected JSONUser
+	}{
+		{
+			name: "Lower case",
+			cfg:  Config{FieldRenamer: strings.ToLower, Include: []string{"email", "address.street"}},
+			expected: JSONUser{FullName: "Alice", Email: "b@x.io",
+				Address: JSONAddress{Street: "New St", PostalCode: "111"}},
+		},
+		{
+			name: "Snake case",
+			cfg:  Config{FieldRenamer: snake, Exclude: []string{"full_name", "address.postal_code"}},
+			expected: JSONUser{FullName: "Alice", Email: "b@x.io",
+				Address: JSONAddress{Street: "New St", PostalCode: "111"}},
+		},
+		{
+			name: "JSON names need UseJSONTags",
+			cfg:  Config{FieldRenamer: strings.ToUpper, Include: []string{"EMAIL", "full_name"}},
+			expected: JSONUser{FullName: "Alice", Email: "b@x.io",
+				Address: JSONAddress{Street: "Old St", PostalCode: "111"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %#v, want %#v", got, tt.expected)
+			}
+			if err := ValidateConfig(tt.cfg, JSONUser{}); tt.name != "JSON names need UseJSONTags" && err != nil {
+				t.Errorf("ValidateConfig() = %v", err)
+			}
+		})
+	}
+}
+
+type Base struct {
+	ID   int
+	Name string
+}
+
+type Customer struct {
+	Base
+	Name  string // hides Base.Name
+	Email string
+}
+
+func TestMergeEmbedded(t *testing.T) {
+	dst := Customer{Base: Base{ID: 1, Name: "base"}, Name: "Alice", Email: "a@x.io"}
+	src := Customer{Base: Base{ID: 2, Name: "other"}, Name: "Bob", Email: "b@x.io"}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected Customer
+	}{
+		{
+			name:     "Include promoted field",
+			cfg:      Config{Include: []string{"ID"}},
+			expected: Customer{Base: Base{ID: 2, Name: "base"}, Name: "Alice", Email: "a@x.io"},
+		},
+		{
+			name:     "Exclude promoted field",
+			cfg:      Config{Exclude: []string{"ID"}},
+			expected: Customer{Base: Base{ID: 1, Name: "base"}, Name: "Bob", Email: "b@x.io"},
+		},
+		{
+			name:     "Outer field hides promoted field",
+			cfg:      Config{Include: []string{"Name"}},
+			expected: Customer{Base: Base{ID: 1, Name: "base"}, Name: "Bob", Email: "a@x.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+type Shape interface{ Area() int }
+
+type Rect struct{ W, H int }
+
+func (r Rect) Area() int { return r.W * r.H }
+
+type Square struct{ Side int }
+
+func (s Square) Area() int { return s.Side * s.Side }
+
+type Canvas struct {
+	Shape Shape
+	Meta  interface{}
+}
+
+func TestMergeInterfaceStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy InterfaceMergeStrategy
+		dst, src Canvas
+		expected Canvas
+	}{
+		{
+			name:     "Replace",
+			strategy: InterfaceReplace,
+			dst:      Canvas{Shape: Rect{W: 2, H: 3}},
+			src:      Canvas{Shape: Rect{H: 5}},
+			expected: Canvas{Shape: Rect{H: 5}},
+		},
+		{
+			name:     "Deep merges structs",
+			strategy: InterfaceMergeDeep,
+			dst:      Canvas{Shape: Rect{W: 2, H: 3}},
+			src:      Canvas{Shape: Rect{H: 5}},
+			expected: Canvas{Shape: Rect{W: 2, H: 5}},
+		},
+		{
+			name:     "Deep merges pointers to structs",
+			strategy: InterfaceMergeDeep,
+			dst:      Canvas{Meta: &Rect{W: 2, H: 3}},
+			src:      Canvas{Meta: &Rect{W: 4}},
+			expected: Canvas{Meta: &Rect{W: 4, H: 3}},
+		},
+		{
+			name:     "Deep replaces different types",
+			strategy: InterfaceMergeDeep,
+			dst:      Canvas{Shape: Rect{W: 2, H: 3}},
+			src:      Canvas{Shape: Square{Side: 4}},
+			expected: Canvas{Shape: Square{Side: 4}},
+		},
+		{
+			name:     "Deep replaces non-struct values",
+			strategy: InterfaceMergeDeep,
+			dst:      Canvas{Meta: "old"},
+			src:      Canvas{Meta: "new"},
+			expected: Canvas{Meta: "new"},
+		},
+		{
+			name:     "Deep fills nil destination",
+			strategy: InterfaceMergeDeep,
+			dst:      Canvas{},
+			src:      Canvas{Shape: Square{Side: 4}},
+			expected: Canvas{Shape: Square{Side: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Option: ExcludeEmpty, InterfaceMergeStrategy: tt.strategy}
+			if err := Merge(&tt.dst, tt.src, cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.dst, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeInterfaceDoesNotModifySharedPointer(t *testing.T) {
+	shared := &Rect{W: 2, H: 3}
+	dst := Canvas{Meta: shared}
+	src := Canvas{Meta: &Rect{W: 4}}
+
+	cfg := Config{Option: ExcludeEmpty, InterfaceMergeStrategy: InterfaceMergeDeep}
+	if err := Merge(&dst, src, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *shared != (Rect{W: 2, H: 3}) {
+		t.Errorf("shared pointer modified: %+v", *shared)
+	}
+}
+
+func TestMergeMaxDepth(t *testing.T) {
+	dst := Level1{Name: "a", Level2: Level2{Name: "b", Level3: Level3{Value: "c", Other: "keep"}}}
+	src := Level1{Name: "x", Level2: Level2{Level3: Level3{Value: "z"}}}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		expected Level1
+	}{
+		{
+			name:     "Depth 1",
+			maxDepth: 1,
+			expected: Level1{Name: "x", Level2: Level2{Level3: Level3{Value: "z"}}},
+		},
+		{
+			name:     "Depth 2",
+			maxDepth: 2,
+			expected: Level1{Name: "x", Level2: Level2{Name: "b", Level3: Level3{Value: "z"}}},
+		},
+		{
+			name:     "Unlimited",
+			expected: Level1{Name: "x", Level2: Level2{Name: "b", Level3: Level3{Value: "z", Other: "keep"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			cfg := Config{Option: ExcludeEmpty, MaxDepth: tt.maxDepth}
+			if err := Merge(&got, src, cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeMaxDepthError(t *testing.T) {
+	var dst Level1
+	src := Level1{Level2: Level2{Level3: Level3{Value: "z"}}}
+
+	err := Merge(&dst, src, Config{MaxDepth: 2, MaxDepthBehavior: MaxDepthError})
+	var fieldErr *FieldError
+	if !errors.Is(err, ErrMaxDepthExceeded) || !errors.As(err, &fieldErr) {
+		t.Fatalf("expected ErrMaxDepthExceeded, got %v", err)
+	}
+	if fieldErr.Path != "Level2.Level3" {
+		t.Errorf("Path = %q, want %q", fieldErr.Path, "Level2.Level3")
+	}
+
+	if err := Merge(&dst, src, Config{MaxDepth: 3, MaxDepthBehavior: MaxDepthError}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type NullableRow struct {
+	Name  sql.NullString
+	Count sql.NullInt64
+	Flag  sql.NullBool
+	Score sql.Null[float64]
+}
+
+func TestMergeSQLNullTypes(t *testing.T) {
+	dst := NullableRow{
+		Name:  sql.NullString{String: "old", Valid: true},
+		Count: sql.NullInt64{Int64: 5, Valid: true},
+		Flag:  sql.NullBool{Bool: true, Valid: true},
+		Score: sql.Null[float64]{V: 1.5, Valid: true},
+	}
+	src := NullableRow{
+		Name:  sql.NullString{String: "", Valid: true},
+		Count: sql.NullInt64{Int64: 7},
+		Score: sql.Null[float64]{V: 2.5, Valid: true},
+	}
+
+	tests := []struct {
+		name     string
+		option   MergeOption
+		dst      NullableRow
+		expected NullableRow
+	}{
+		{
+			name:   "ExcludeEmpty skips invalid values",
+			option: ExcludeEmpty,
+			dst:    dst,
+			expected: NullableRow{
+				Name:  sql.NullString{String: "", Valid: true},
+				Count: sql.NullInt64{Int64: 5, Valid: true},
+				Flag:  sql.NullBool{Bool: true, Valid: true},
+				Score: sql.Null[float64]{V: 2.5, Valid: true},
+			},
+		},
+		{
+			name:   "OverwriteEmpty fills invalid values",
+			option: OverwriteEmpty,
+			dst:    NullableRow{Count: sql.NullInt64{Int64: 1}},
+			expected: NullableRow{
+				Name:  sql.NullString{String: "", Valid: true},
+				Count: sql.NullInt64{Int64: 7},
+				Score: sql.Null[float64]{V: 2.5, Valid: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.dst
+			if err := Merge(&got, src, Config{Option: tt.option}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeFieldPredicate(t *testing.T) {
+	dst := TestStruct{Name: "Alice", Age: 30, Address: Address{Street: "Old St", City: "Kampala"}}
+	src := TestStruct{Name: "Bob", Age: 150, Address: Address{Street: "New St", City: "Gulu"}}
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		expected  TestStruct
+		wantPaths []string
+	}{
+		{
+			name: "Skip values above threshold",
+			cfg: Config{FieldPredicate: func(path string, dst, src reflect.Value) bool {
+				return src.Kind() != reflect.Int || src.Int() <= 120
+			}},
+			expected: TestStruct{Name: "Bob", Age: 30, Address: Address{Street: "New St", City: "Gulu"}},
+		},
+		{
+			name: "Skip nested struct",
+			cfg: Config{FieldPredicate: func(path string, dst, src reflect.Value) bool {
+				return path != "Address"
+			}},
+			expected: TestStruct{Name: "Bob", Age: 150, Address: Address{Street: "Old St", City: "Kampala"}},
+		},
+		{
+			name: "Runs after Include",
+			cfg: Config{
+				Include: []string{"Name", "Address.City"},
+				FieldPredicate: func(path string, dst, src reflect.Value) bool {
+					return true
+				},
+			},
+			expected:  TestStruct{Name: "Bob", Age: 30, Address: Address{Street: "Old St", City: "Gulu"}},
+			wantPaths: []string{"Name", "Address", "Address.City"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			predicate := tt.cfg.FieldPredicate
+			tt.cfg.FieldPredicate = func(path string, dst, src reflect.Value) bool {
+				paths = append(paths, path)
+				return predicate(path, dst, src)
+			}
+
+			got := dst
+			if err := Merge(&got, src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.expected)
+			}
+			if tt.wantPaths != nil && !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("predicate called for %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestMergeConflictResolver(t *testing.T) {
+	var calls []string
+	takeMax := func(path string, dst, src reflect.Value) reflect.Value {
+		calls = append(calls, path)
+		if dst.Kind() != reflect.Int {
+			return reflect.Value{} // keep dst
+		}
+		if src.Int() > dst.Int() {
+			return src
+		}
+		return dst
+	}
+
+	dst := TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Kampala"}}
+	src := TestStruct{Name: "Bob", Age: 25, Address: Address{Street: "Main St"}, Count: 3}
+
+	if err := Merge(&dst, src, Config{ConflictResolver: takeMax}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Name is kept by the resolver, Address.City is overwritten with the
+	// empty source value without a conflict.
+	expected := TestStruct{Name: "Alice", Age: 30, Address: Address{Street: "Main St"}, Count: 3}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Merge() = %+v, want %+v", dst, expected)
+	}
+	if want := []string{"Name", "Age"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("resolver called for %v, want %v", calls, want)
+	}
+
+	dst.Age = 20
+	if err := Merge(&dst, src, Config{ConflictResolver: takeMax}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Age != 25 {
+		t.Errorf("Age = %d, want 25", dst.Age)
+	}
+}
+
+type Blob struct {
+	Data []byte
+	Raw  json.RawMessage
+	IDs  []int
+}
+
+func TestMergeByteSliceStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy ByteSliceMergeStrategy
+		expected Blob
+	}{
+		{
+			name:     "Replace",
+			strategy: ByteSliceReplace,
+			expected: Blob{Data: []byte("cd"), Raw: json.RawMessage(`{"b":2}`), IDs: []int{2}},
+		},
+		{
+			name:     "Append",
+			strategy: ByteSliceAppend,
+			expected: Blob{Data: []byte("abcd"), Raw: json.RawMessage(`{"b":2}`), IDs: []int{2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := Blob{Data: []byte("ab"), Raw: json.RawMessage(`{"a":1}`), IDs: []int{1}}
+			src := Blob{Data: []byte("cd"), Raw: json.RawMessage(`{"b":2}`), IDs: []int{2}}
+
+			cfg := Config{ByteSliceMergeStrategy: tt.strategy}
+			if err := Merge(&dst, src, cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.expected) {
+				t.Errorf("Merge() = %+v, want %+v", dst, tt.expected)
+			}
+		})
+	}
+}
+
+type RetryPolicy struct {
+	Delay   time.Duration
+	Retries int
+}
+
+func TestMergeZeroDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		dst, src RetryPolicy
+		expected RetryPolicy
+	}{
+		{
+			name:     "ExcludeEmpty skips zero duration",
+			cfg:      Config{Option: ExcludeEmpty},
+			dst:      RetryPolicy{Delay: time.Second, Retries: 3},
+			src:      RetryPolicy{Delay: 0, Retries: 5},
+			expected: RetryPolicy{Delay: time.Second, Retries: 5},
+		},
+		{
+			name:     "ExcludeEmpty keeps zero duration",
+			cfg:      Config{Option: ExcludeEmpty, KeepZeroDurations: true},
+			dst:      RetryPolicy{Delay: time.Second, Retries: 3},
+			src:      RetryPolicy{Delay: 0, Retries: 0},
+			expected: RetryPolicy{Delay: 0, Retries: 3},
+		},
+		{
+			name:     "OverwriteEmpty leaves zero duration",
+			cfg:      Config{Option: OverwriteEmpty, KeepZeroDurations: true},
+			dst:      RetryPolicy{},
+			src:      RetryPolicy{Delay: time.Second, Retries: 5},
+			expected: RetryPolicy{Retries: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.dst != tt.expected {
+				t.Errorf("Merge() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+type FeatureFlags struct {
+	Name    string
+	Enabled bool
+}
+
+func TestMergeKeepFalseBools(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		dst, src FeatureFlags
+		expected FeatureFlags
+	}{
+		{
+			name:     "ExcludeEmpty skips false",
+			cfg:      Config{Option: ExcludeEmpty},
+			dst:      FeatureFlags{Name: "beta", Enabled: true},
+			src:      FeatureFlags{Enabled: false},
+			expected: FeatureFlags{Name: "beta", Enabled: true},
+		},
+		{
+			name:     "ExcludeEmpty keeps false",
+			cfg:      Config{Option: ExcludeEmpty, KeepFalseBools: true},
+			dst:      FeatureFlags{Name: "beta", Enabled: true},
+			src:      FeatureFlags{Enabled: false},
+			expected: FeatureFlags{Name: "beta", Enabled: false},
+		},
+		{
+			name:     "OverwriteEmpty leaves false",
+			cfg:      Config{Option: OverwriteEmpty, KeepFalseBools: true},
+			dst:      FeatureFlags{},
+			src:      FeatureFlags{Name: "beta", Enabled: true},
+			expected: FeatureFlags{Name: "beta"},
+		},
+		{
+			name:     "FillEmpty leaves false",
+			cfg:      Config{Option: FillEmpty, KeepFalseBools: true, TreatBoolFalseAsZero: true},
+			dst:      FeatureFlags{},
+			src:      FeatureFlags{Name: "beta", Enabled: true},
+			expected: FeatureFlags{Name: "beta"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Merge(&tt.dst, tt.src, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.dst != tt.expected {
+				t.Errorf("Merge() = %+v, want %+v", tt.dst, tt.expected)
+			}
+		})
+	}
+}
+
+type Release struct {
+	Name    string
+	App     Version
+	Lib     Version
+	Details struct {
+		API Version
+	}
+	Notes string
+}
+
+func TestMergeContinueOnError(t *testing.T) {
+	dst := Release{Name: "old", App: 3, Lib: 2, Notes: "old"}
+	dst.Details.API = 5
+	src := Release{Name: "new", App: 1, Lib: 4, Notes: "new"}
+	src.Details.API = 4
+
+	// By default the merge stops at the first error.
+	got := dst
+	err := Merge(&got, src)
+	var multi MultiError
+	if !errors.Is(err, errBadVersion) || errors.As(err, &multi) {
+		t.Fatalf("expected a single error, got %v", err)
+	}
+	if got.Notes != "old" {
+		t.Errorf("expected the merge to stop, got %+v", got)
+	}
+
+	got = dst
+	err = Merge(&got, src, Config{ContinueOnError: true})
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected MultiError, got %v", err)
+	}
+
+	var paths []string
+	for _, e := range multi {
+		var fieldErr *FieldError
+		if !errors.As(e, &fieldErr) || !errors.Is(e, errBadVersion) {
+			t.Fatalf("unexpected error %v", e)
+		}
+		paths = append(paths, fieldErr.Path)
+	}
+	if want := []string{"App", "Details.API"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("error paths = %v, want %v", paths, want)
+	}
+
+	// The other fields are still merged.
+	expected := Release{Name: "new", App: 3, Lib: 4, Notes: "new"}
+	expected.Details.API = 5
+	if got != expected {
+		t.Errorf("Merge() = %+v, want %+v", got, expected)
+	}
+}
+
+var errBroken = errors.New("broken")
+
+// Broken is a Merger that always fails.
+type Broken string
+
+func (b *Broken) Merge(src reflect.Value) error {
+	return errBroken
+}
+
+type Component struct {
+	Name   string
+	Status Broken
+	Nested struct {
+		Status Broken
+		Count  int
+	}
+}
+
+func TestMergeOnError(t *testing.T) {
+	src := Component{Name: "new"}
+	src.Nested.Count = 2
+
+	// Returning nil skips the failing fields.
+	var paths []string
+	dst := Component{Name: "old"}
+	err := Merge(&dst, src, Config{OnError: func(path string, err error) error {
+		if !errors.Is(err, errBroken) {
+			t.Errorf("unexpected error %v", err)
+		}
+		paths = append(paths, path)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Status", "Nested.Status"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("OnError called for %v, want %v", paths, want)
+	}
+	if dst.Name != "new" || dst.Nested.Count != 2 {
+		t.Errorf("expected the other fields to be merged, got %+v", dst)
+	}
+
+	// Returning an error stops the merge with that error, even under
+	// ContinueOnError, and OnError is not called again on the way up.
+	errStop := errors.New("stop")
+	calls := 0
+	dst = Component{Name: "old"}
+	err = Merge(&dst, src, Config{ContinueOnError: true, OnError: func(path string, err error) error {
+		calls++
+		if path == "Nested.Status" {
+			return errStop
+		}
+		return nil
+	}})
+	if err != errStop || calls != 2 {
+		t.Errorf("expected errStop after 2 calls, got %v after %d", err, calls)
+	}
+	if dst.Nested.Count != 0 {
+		t.Errorf("expected the merge to stop, got %+v", dst)
+	}
+}
+
+func TestMergeFromMapContinueOnError(t *testing.T) {
+	dst := Release{App: 3, Lib: 2}
+	src := map[string]interface{}{"App": 1, "Lib": 1, "Name": "new"}
+
+	err := MergeFromMap(&dst, src, Config{ContinueOnError: true})
+	var multi MultiError
+	if !errors.As(err, &multi) || len(multi) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if dst.Name != "new" {
+		t.Errorf("Name = %q, want new", dst.Name)
+	}
+}
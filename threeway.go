@@ -0,0 +1,130 @@
+package structmerge
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConflictError is returned by ThreeWayMerge when local and remote changed
+// the same fields in incompatible ways.
+type ConflictError struct {
+	Paths []string // the conflicting field paths
+}
+
+func (e *ConflictError) Error() string {
+	return "conflicting changes: " + strings.Join(e.Paths, ", ")
+}
+
+// ThreeWayMerge applies the changes made from base to local and from base to
+// remote to a deep copy of base, which is returned with the same type as
+// base. All three must be structs (or pointers to structs) of the same type.
+//
+// A field changed on both sides is a conflict if the two sides changed it to
+// different non-zero values, or if one side changed a nested field while the
+// other replaced its parent. Conflicting fields keep their base value and are
+// listed in a *ConflictError, returned together with the merged result so
+// that the caller can resolve them. If only one side cleared a field the
+// other changed, the non-zero value wins.
+//
+// The Include, Groups and Exclude settings of cfg restrict which fields are
+// merged; other fields keep their base value.
+func ThreeWayMerge(base, local, remote interface{}, cfg ...Config) (interface{}, error) {
+	localPaths, err := Diff(base, local)
+	if err != nil {
+		return nil, err
+	}
+
+	remotePaths, err := Diff(base, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := DeepCopy(base)
+	if err != nil {
+		return nil, err
+	}
+
+	result := reflect.New(reflect.TypeOf(out))
+	result.Elem().Set(reflect.ValueOf(out))
+	dst := result
+	if dst.Elem().Kind() == reflect.Ptr {
+		dst = dst.Elem()
+	}
+
+	localV, _ := structValue(local)
+	remoteV, _ := structValue(remote)
+	filter := newPathFilter(resolveConfig(localV.Type(), configOf(cfg)))
+
+	changes := make(map[string]reflect.Value)
+	for _, path := range localPaths {
+		if !filter.skip(path, false) {
+			changes[path], _ = lookupPath(localV, path, false)
+		}
+	}
+
+	var conflicts []string
+	for _, path := range remotePaths {
+		if filter.skip(path, false) {
+			continue
+		}
+
+		remoteVal, _ := lookupPath(remoteV, path, false)
+		localVal, ok := changes[path]
+		switch {
+		case ok:
+			switch {
+			case reflect.DeepEqual(localVal.Interface(), remoteVal.Interface()):
+			case isZero(localVal):
+				changes[path] = remoteVal
+			case isZero(remoteVal):
+			default:
+				delete(changes, path)
+				conflicts = append(conflicts, path)
+			}
+		case overlapsAny(path, changes):
+			conflicts = append(conflicts, path)
+		default:
+			changes[path] = remoteVal
+		}
+	}
+
+	// Overlapping paths conflict on both sides.
+	for _, path := range conflicts {
+		for changed := range changes {
+			if overlaps(path, changed) {
+				delete(changes, changed)
+				conflicts = append(conflicts, changed)
+			}
+		}
+	}
+
+	for path, value := range changes {
+		field, err := lookupPath(dst.Elem(), path, true)
+		if err != nil {
+			return nil, err
+		}
+		field.Set(value)
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return result.Elem().Interface(), &ConflictError{Paths: conflicts}
+	}
+	return result.Elem().Interface(), nil
+}
+
+// overlaps reports whether one of the paths a and b is nested in the other.
+func overlaps(a, b string) bool {
+	return strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+// overlapsAny reports whether path overlaps one of the paths in changes.
+func overlapsAny(path string, changes map[string]reflect.Value) bool {
+	for changed := range changes {
+		if overlaps(path, changed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	base := TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}}
+
+	tests := []struct {
+		name      string
+		local     TestStruct
+		remote    TestStruct
+		cfg       []Config
+		expected  TestStruct
+		conflicts []string
+	}{
+		{
+			name:     "No changes",
+			local:    base,
+			remote:   base,
+			expected: base,
+		},
+		{
+			name:     "Local change only",
+			local:    TestStruct{Name: "Alicia", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}},
+			remote:   base,
+			expected: TestStruct{Name: "Alicia", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}},
+		},
+		{
+			name:     "Remote change only",
+			local:    base,
+			remote:   TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+			expected: TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+		},
+		{
+			name:     "Disjoint changes",
+			local:    TestStruct{Name: "Alicia", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}},
+			remote:   TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}},
+			expected: TestStruct{Name: "Alicia", Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}},
+		},
+		{
+			name:     "Same change on both sides",
+			local:    TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+			remote:   TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+			expected: TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+		},
+		{
+			name:     "Cleared on one side",
+			local:    TestStruct{Name: "Alice", Age: 30, Address: Address{Country: "Uganda"}},
+			remote:   TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}},
+			expected: TestStruct{Name: "Alice", Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}},
+		},
+		{
+			name:      "Conflicting changes",
+			local:     TestStruct{Name: "Alicia", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+			remote:    TestStruct{Name: "Ali", Age: 30, Address: Address{City: "Gulu", Country: "Uganda"}},
+			expected:  TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Gulu", Country: "Uganda"}},
+			conflicts: []string{"Name"},
+		},
+		{
+			name:     "Exclude",
+			local:    TestStruct{Name: "Alicia", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}},
+			remote:   TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Kampala", Country: "Uganda"}},
+			cfg:      []Config{{Exclude: []string{"Age"}}},
+			expected: TestStruct{Name: "Alicia", Age: 30, Address: Address{City: "Kampala", Country: "Uganda"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ThreeWayMerge(base, tt.local, tt.remote, tt.cfg...)
+
+			var conflictErr *ConflictError
+			if tt.conflicts != nil {
+				if !errors.As(err, &conflictErr) {
+					t.Fatalf("expected ConflictError, got %v", err)
+				}
+				if !reflect.DeepEqual(conflictErr.Paths, tt.conflicts) {
+					t.Errorf("conflicts = %v, want %v", conflictErr.Paths, tt.conflicts)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := out.(TestStruct); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ThreeWayMerge() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestThreeWayMergePointers(t *testing.T) {
+	base := &Team{Name: "core", Lead: &Person{Name: "Alice"}}
+	local := &Team{Name: "core", Lead: &Person{Name: "Alice", Age: 30}}
+	remote := &Team{Name: "core"}
+
+	out, err := ThreeWayMerge(base, local, remote)
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if want := []string{"Lead", "Lead.Age"}; !reflect.DeepEqual(conflictErr.Paths, want) {
+		t.Errorf("conflicts = %v, want %v", conflictErr.Paths, want)
+	}
+
+	got := out.(*Team)
+	if got.Lead == base.Lead || !reflect.DeepEqual(got, base) {
+		t.Errorf("expected a copy of base, got %+v", got)
+	}
+}
+
+func TestThreeWayMergeTypeMismatch(t *testing.T) {
+	if _, err := ThreeWayMerge(Person{}, Person{}, Address{}); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+}
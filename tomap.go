@@ -0,0 +1,65 @@
+package structmerge
+
+import "reflect"
+
+// ToMap flattens src, a struct or a pointer to a struct, into a map from
+// dot-separated field paths, as used by Config.Include, to field values.
+// Nested structs and non-nil pointers to structs are flattened recursively;
+// a nil pointer, or one leading back to a struct being flattened, is stored
+// as it is under its own path. time.Time values,
+// Merger implementations and nullable database types are kept whole.
+// Unexported fields and fields tagged with `merge:"-"` are left out.
+func ToMap(src interface{}) (map[string]interface{}, error) {
+	v, err := structValue(src)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{})
+	visiting := make(map[uintptr]bool)
+	if rv := reflect.ValueOf(src); rv.Kind() == reflect.Ptr {
+		visiting[rv.Pointer()] = true
+	}
+	toMap(v, "", m, visiting)
+	return m, nil
+}
+
+// toMap flattens the struct v into m. visiting holds the addresses of the
+// structs being flattened, to detect cycles.
+func toMap(v reflect.Value, prefix string, m map[string]interface{}, visiting map[uintptr]bool) {
+	for _, field := range cachedMeta(v.Type()).fields {
+		if !field.exported {
+			continue
+		}
+
+		path := prefix + field.name
+		fv := v.FieldByIndex(field.index)
+		if fv.Kind() == reflect.Ptr && !fv.IsNil() && isNestedStruct(fv.Type().Elem()) {
+			addr := fv.Pointer()
+			if visiting[addr] {
+				m[path] = fv.Interface()
+				continue
+			}
+			visiting[addr] = true
+			toMap(fv.Elem(), path+".", m, visiting)
+			delete(visiting, addr)
+			continue
+		}
+
+		if isNestedStruct(fv.Type()) {
+			toMap(fv, path+".", m, visiting)
+			continue
+		}
+		m[path] = fv.Interface()
+	}
+}
+
+// FromMap is the inverse of ToMap: it assigns the values of m, keyed by
+// dot-separated field paths, to the fields of dst, which must be a pointer
+// to a struct. Nil pointers along a path are allocated and values are
+// converted to the field types as in ApplyPatch. Fields missing from m are
+// left untouched. A path that does not exist fails with ErrInvalidPath and
+// leaves dst unchanged.
+func FromMap(dst interface{}, m map[string]interface{}) error {
+	return ApplyPatch(dst, &Patch{Changes: m})
+}
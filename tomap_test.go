@@ -0,0 +1,111 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToMap(t *testing.T) {
+	founded := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	team := Team{
+		Name:    "core",
+		Lead:    &Person{Name: "Alice", Age: 30},
+		Members: []string{"a", "b"},
+		Founded: founded,
+	}
+
+	m, err := ToMap(&team)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Name":         "core",
+		"Lead.Name":    "Alice",
+		"Lead.Age":     30,
+		"Lead.Address": (*Address)(nil),
+		"Lead.Active":  false,
+		"Members":      []string{"a", "b"},
+		"Budget":       (*int)(nil),
+		"Founded":      founded,
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("ToMap() = %#v, want %#v", m, expected)
+	}
+}
+
+func TestToMapCycle(t *testing.T) {
+	a := &Node{Name: "a"}
+	b := &Node{Name: "b", Next: a}
+	a.Next = b
+
+	// The pointer leading back to a is stored as it is.
+	m, err := ToMap(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{"Name": "a", "Next.Name": "b", "Next.Next": a}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("ToMap() = %v, want %v", m, expected)
+	}
+
+	// A copy of a is flattened once more before the cycle is detected.
+	m, err = ToMap(*a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m["Next.Next.Name"] != "a" || m["Next.Next.Next"] != b {
+		t.Errorf("ToMap() = %v", m)
+	}
+}
+
+func TestToMapFromMapRoundTrip(t *testing.T) {
+	budget := 100
+	tests := []struct {
+		name string
+		src  Team
+	}{
+		{"Empty", Team{}},
+		{"Nested pointers", Team{Name: "core", Lead: &Person{Name: "Alice", Address: &Address{City: "Kampala"}}}},
+		{"All fields", Team{Name: "ops", Members: []string{"x"}, Budget: &budget, Founded: time.Now().UTC()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ToMap(tt.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got Team
+			if err := FromMap(&got, m); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.src) {
+				t.Errorf("round trip = %+v, want %+v", got, tt.src)
+			}
+		})
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	got := TestStruct{Name: "Alice", Age: 30}
+	err := FromMap(&got, map[string]interface{}{"Address.City": "Gulu", "Age": 31.0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := TestStruct{Name: "Alice", Age: 31, Address: Address{City: "Gulu"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("FromMap() = %+v, want %+v", got, expected)
+	}
+
+	if err := FromMap(&got, map[string]interface{}{"Adress.City": "x"}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+	if _, err := ToMap(42); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !structmerge_unsafe
+
+package structmerge
+
+import "reflect"
+
+// unsafeEnabled reports whether the package was built with the
+// structmerge_unsafe tag, which makes Config.UseUnsafe take effect.
+const unsafeEnabled = false
+
+// exposeField returns v unchanged: without the structmerge_unsafe build tag
+// unexported fields are never written.
+func exposeField(v reflect.Value) reflect.Value {
+	return v
+}
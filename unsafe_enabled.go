@@ -0,0 +1,22 @@
+//go:build structmerge_unsafe
+
+package structmerge
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// unsafeEnabled reports whether the package was built with the
+// structmerge_unsafe tag, which makes Config.UseUnsafe take effect.
+const unsafeEnabled = true
+
+// exposeField returns a settable view of the addressable field v, bypassing
+// the restriction reflect places on unexported fields. The result aliases v.
+// Values that are not addressable are returned unchanged.
+func exposeField(v reflect.Value) reflect.Value {
+	if v.CanSet() || !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
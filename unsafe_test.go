@@ -0,0 +1,33 @@
+package structmerge
+
+import "testing"
+
+type Session struct {
+	ID    string
+	token string
+	hits  int
+	home  Address
+}
+
+func TestMergeUseUnsafe(t *testing.T) {
+	dst := Session{ID: "a", token: "old", hits: 3, home: Address{City: "Kampala"}}
+	src := Session{ID: "b", token: "new", home: Address{Street: "Main St"}}
+
+	if err := Merge(&dst, src, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Session{ID: "b", token: "old", hits: 3, home: Address{City: "Kampala"}}
+	if dst != expected {
+		t.Fatalf("without UseUnsafe got %+v, want %+v", dst, expected)
+	}
+
+	if err := Merge(&dst, src, Config{Option: ExcludeEmpty, UseUnsafe: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unsafeEnabled {
+		expected = Session{ID: "b", token: "new", hits: 3, home: Address{Street: "Main St", City: "Kampala"}}
+	}
+	if dst != expected {
+		t.Errorf("with UseUnsafe got %+v, want %+v", dst, expected)
+	}
+}
@@ -0,0 +1,154 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrorList is a list of errors reported together.
+// errors.Is and errors.As match any of the errors in the list.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+// MultiError holds the errors of a merge run with Config.ContinueOnError.
+type MultiError = ErrorList
+
+// ValidateConfig checks that every path in cfg.Include and cfg.Exclude names
+// a field of structType, which may be a struct, a pointer to a struct or a
+// reflect.Type of either. Paths can be nested to any depth and may cross
+// pointer fields. All invalid paths are reported in an ErrorList of
+// *FieldError values wrapping ErrInvalidPath.
+//
+// Exclude takes precedence over Include, so excluding a field nested in an
+// included one, as with Include "Address" and Exclude "Address.City", leaves
+// that field out. An Include path that is also excluded, or that lies within
+// an excluded field, can never take effect and is reported as a *FieldError
+// wrapping ErrConflictingPaths. Setting IncludeFunc together with Include
+// or Groups is reported as ErrConflictingPaths too. Fields selected through
+// Groups may be excluded.
+func ValidateConfig(cfg Config, structType interface{}) error {
+	t, err := typeOf(structType)
+	if err != nil {
+		return err
+	}
+
+	explicit := len(cfg.Include)
+	cfg = resolveConfig(t, cfg)
+	include := cfg.Include[:explicit]
+
+	var errs ErrorList
+	for _, paths := range [][]string{include, cfg.Exclude} {
+		for _, path := range paths {
+			if err := checkPath(t, path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, path := range include {
+		if isExcluded(path, cfg.Exclude) {
+			errs = append(errs, &FieldError{Path: path, Err: ErrConflictingPaths})
+		}
+	}
+	if len(include) > 0 && cfg.IncludeFunc != nil {
+		errs = append(errs, &MergeError{
+			Code:  ErrCodeConflictingPaths,
+			Cause: errors.New("both Include and IncludeFunc are set"),
+		})
+	}
+	if len(cfg.Groups) > 0 && cfg.IncludeFunc != nil {
+		errs = append(errs, &MergeError{
+			Code:  ErrCodeConflictingPaths,
+			Cause: errors.New("both Groups and IncludeFunc are set"),
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// isExcluded reports whether path, or one of its parents, is matched by one
+// of the exclude paths.
+func isExcluded(path string, exclude []string) bool {
+	segments := strings.Split(path, ".")
+	for _, exc := range exclude {
+		p := compilePattern(exc)
+		for i := 1; i <= len(segments); i++ {
+			if p.match(segments[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FieldNames returns the dot-separated paths of the fields of structType,
+// which may be a struct, a pointer to a struct or a reflect.Type of either,
+// in declaration order. Nested structs and pointers to structs are listed by
+// the paths of their own fields; fields of embedded structs are promoted.
+// time.Time values, Merger implementations, nullable database types and
+// pointers back to a type being listed are listed as single fields.
+// Unexported fields, fields tagged with `merge:"-"` and masked fields are
+// left out.
+func FieldNames(structType interface{}) ([]string, error) {
+	t, err := typeOf(structType)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	fieldNames(t, "", map[reflect.Type]bool{t: true}, &names)
+	return names, nil
+}
+
+func fieldNames(t reflect.Type, prefix string, visiting map[reflect.Type]bool, names *[]string) {
+	for _, field := range cachedMeta(t).fields {
+		if !field.exported || field.masked {
+			continue
+		}
+
+		path := prefix + field.name
+		ft := t.FieldByIndex(field.index).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if !isNestedStruct(ft) || visiting[ft] {
+			*names = append(*names, path)
+			continue
+		}
+
+		visiting[ft] = true
+		fieldNames(ft, path+".", visiting, names)
+		delete(visiting, ft)
+	}
+}
+
+// typeOf returns the struct type of v, which may be a struct, a pointer to
+// a struct or a reflect.Type of either.
+func typeOf(v interface{}) (reflect.Type, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrInvalidSource
+	}
+	return t, nil
+}
@@ -0,0 +1,193 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Company struct {
+	Name    string
+	HQ      *Location
+	Offices map[string]Address
+	Owner   Person
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := Config{
+		Include: []string{"Name", "HQ.Address.Street", "Offices.kampala.City", "Owner.Address.Country"},
+		Exclude: []string{"HQ.Geo"},
+	}
+
+	for _, typ := range []interface{}{Company{}, &Company{}, reflect.TypeOf(&Company{})} {
+		if err := ValidateConfig(valid, typ); err != nil {
+			t.Errorf("ValidateConfig(%T): unexpected error: %v", typ, err)
+		}
+	}
+}
+
+func TestValidateConfigInvalidPaths(t *testing.T) {
+	cfg := Config{
+		Include: []string{"Name", "HQ.Address.Stret", "Owner.Nickname.First"},
+		Exclude: []string{"Name.First", "Offices"},
+	}
+
+	err := ValidateConfig(cfg, &Company{})
+
+	var list ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected an ErrorList, got %#v", err)
+	}
+
+	var paths []string
+	for _, e := range list {
+		var fieldErr *FieldError
+		if !errors.As(e, &fieldErr) {
+			t.Fatalf("expected a *FieldError, got %#v", e)
+		}
+		paths = append(paths, fieldErr.Path)
+	}
+
+	expected := []string{"HQ.Address.Stret", "Owner.Nickname.First", "Name.First"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("invalid paths = %v, want %v", paths, expected)
+	}
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Error("expected errors.Is to match ErrInvalidPath")
+	}
+}
+
+func TestValidateConfigConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected []string
+	}{
+		{
+			name: "Exclude within include",
+			cfg:  Config{Include: []string{"Owner"}, Exclude: []string{"Owner.Address.City"}},
+		},
+		{
+			name:     "Exact overlap",
+			cfg:      Config{Include: []string{"Name", "Owner.Age"}, Exclude: []string{"Owner.Age"}},
+			expected: []string{"Owner.Age"},
+		},
+		{
+			name:     "Include within exclude",
+			cfg:      Config{Include: []string{"Owner.Address.City", "HQ.Geo"}, Exclude: []string{"Owner"}},
+			expected: []string{"Owner.Address.City"},
+		},
+		{
+			name:     "Include within excluded pattern",
+			cfg:      Config{Include: []string{"Name", "HQ.Geo.Lat", "Owner.*"}, Exclude: []string{"HQ.*", "Owner"}},
+			expected: []string{"HQ.Geo.Lat", "Owner.*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(tt.cfg, Company{})
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var list ErrorList
+			if !errors.As(err, &list) {
+				t.Fatalf("expected an ErrorList, got %#v", err)
+			}
+			var paths []string
+			for _, e := range list {
+				var fieldErr *FieldError
+				if !errors.As(e, &fieldErr) || !errors.Is(e, ErrConflictingPaths) {
+					t.Fatalf("expected a *FieldError wrapping ErrConflictingPaths, got %#v", e)
+				}
+				paths = append(paths, fieldErr.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("conflicting paths = %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+
+	// Exclude takes precedence over Include when merging.
+	src := Company{Name: "Acme", Owner: Person{Name: "Alice", Address: &Address{City: "Gulu"}}}
+	dst := Company{Owner: Person{Age: 40}}
+	if err := Merge(&dst, src, Config{Include: []string{"Owner"}, Exclude: []string{"Owner.Age"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Name != "" || dst.Owner.Name != "Alice" || dst.Owner.Age != 40 {
+		t.Errorf("unexpected result %+v", dst)
+	}
+}
+
+func TestValidateConfigJSONTags(t *testing.T) {
+	cfg := Config{UseJSONTags: true, Include: []string{"address.postal_code"}}
+	if err := ValidateConfig(cfg, JSONUser{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateConfigInvalidType(t *testing.T) {
+	if err := ValidateConfig(Config{}, 42); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+	if err := ValidateConfig(Config{}, nil); err != ErrInvalidSource {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
+
+func TestFieldNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      interface{}
+		expected []string
+	}{
+		{
+			name:     "Nested struct",
+			typ:      TestStruct{},
+			expected: []string{"Name", "Age", "Address.Street", "Address.City", "Address.Country", "Active", "Count"},
+		},
+		{
+			name:     "Pointer fields and time",
+			typ:      &Team{},
+			expected: []string{"Name", "Lead.Name", "Lead.Age", "Lead.Address.Street", "Lead.Address.City", "Lead.Address.Country", "Lead.Active", "Members", "Budget", "Founded"},
+		},
+		{
+			name:     "Embedded struct",
+			typ:      reflect.TypeOf(Customer{}),
+			expected: []string{"ID", "Name", "Email"},
+		},
+		{
+			name:     "Recursive type",
+			typ:      Node{},
+			expected: []string{"Name", "Next"},
+		},
+		{
+			name:     "Merger and skipped fields",
+			typ:      Document{},
+			expected: []string{"Meta.Version", "Meta.Checksum"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := FieldNames(tt.typ)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("FieldNames() = %v, want %v", names, tt.expected)
+			}
+			if err := ValidateConfig(Config{Include: names}, tt.typ); err != nil {
+				t.Errorf("FieldNames() returned invalid paths: %v", err)
+			}
+		})
+	}
+
+	if _, err := FieldNames(42); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
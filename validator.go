@@ -0,0 +1,145 @@
+package structmerge
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// validators maps a validation expression or rule to its registered
+// func(reflect.Value) error.
+var validators sync.Map
+
+// RegisterValidator registers fn to check the values assigned to fields
+// tagged `merge:"validate=<expr>"`, where expr is either tagExpr itself or a
+// comma-separated list of rules one of which is tagExpr. fn sees the value
+// the field would hold, after any append or map merge, and returns an error
+// to reject it. Registering a nil fn removes the validator. It is safe for
+// concurrent use.
+//
+// The rules "min=<n>" and "max=<n>" are built in: they bound numbers, and
+// the length of strings (in runes), slices, arrays and maps.
+func RegisterValidator(tagExpr string, fn func(reflect.Value) error) {
+	if fn == nil {
+		validators.Delete(tagExpr)
+		return
+	}
+	validators.Store(tagExpr, fn)
+}
+
+// ValidationError reports a value rejected by a `merge:"validate=..."` tag.
+type ValidationError struct {
+	Path  string      // dot-separated field path, as in Config.Include
+	Expr  string      // the validation expression of the tag
+	Value interface{} // the rejected value
+	Err   error       // the reason given by the validator
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: value %v fails %q: %v", e.Path, e.Value, e.Expr, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// tagValueOptions lists the merge tag options given with a value.
+var tagValueOptions = []string{"group", "key", "strategy", "default", "validate"}
+
+// isTagOption reports whether name is a merge tag option rather than part
+// of the value of the option before it.
+func isTagOption(name string) bool {
+	if tagFlags[name] {
+		return true
+	}
+	for _, option := range tagValueOptions {
+		if strings.HasPrefix(name, option+"=") || strings.HasPrefix(name, option+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateExpr returns the expression of the `merge:"validate=..."` option.
+// Since rules are separated by commas, as in `merge:"validate=min=0,max=9"`,
+// the expression extends up to the next merge tag option.
+func validateExpr(opts tagOptions) (string, bool) {
+	if !strings.Contains(string(opts), "validate") {
+		return "", false
+	}
+
+	var rules []string
+	found := false
+	for _, name := range strings.Split(string(opts), ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.HasPrefix(name, "validate=") || strings.HasPrefix(name, "validate:"):
+			found = true
+			rules = append(rules, name[len("validate="):])
+		case found && !isTagOption(name):
+			rules = append(rules, name)
+		case found:
+			return strings.Join(rules, ","), true
+		}
+	}
+	return strings.Join(rules, ","), found
+}
+
+// validateValue checks v against expr: with the validator registered for
+// the whole expression if there is one, and rule by rule otherwise.
+func validateValue(expr string, v reflect.Value) error {
+	if fn, ok := validators.Load(expr); ok {
+		return fn.(func(reflect.Value) error)(v)
+	}
+
+	for _, rule := range strings.Split(expr, ",") {
+		if fn, ok := validators.Load(rule); ok {
+			if err := fn.(func(reflect.Value) error)(v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, param, _ := strings.Cut(rule, "=")
+		if name != "min" && name != "max" {
+			return fmt.Errorf("unknown validator %q", rule)
+		}
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid validator %q: %w", rule, err)
+		}
+
+		n, ok := measure(v)
+		if !ok {
+			return fmt.Errorf("%s cannot be applied to %s", name, v.Type())
+		}
+		if name == "min" && n < bound {
+			return fmt.Errorf("less than %s", param)
+		}
+		if name == "max" && n > bound {
+			return fmt.Errorf("greater than %s", param)
+		}
+	}
+	return nil
+}
+
+// measure returns the quantity the min and max rules bound: the value of a
+// number or the length of a string, slice, array or map.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,96 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Rating struct {
+	Score int      `merge:"validate=min=0,max=100"`
+	Title string   `merge:"validate=min=1,omitempty"`
+	Code  string   `merge:"validate=upper"`
+	Tags  []string `merge:"append,validate=max=2"`
+}
+
+func TestValidateExpr(t *testing.T) {
+	tests := []struct {
+		opts tagOptions
+		expr string
+		ok   bool
+	}{
+		{"validate=min=0,max=100", "min=0,max=100", true},
+		{"omitempty,validate=upper,append", "upper", true},
+		{"validate=min=1,group=pii", "min=1", true},
+		{"append", "", false},
+	}
+
+	for _, tt := range tests {
+		expr, ok := validateExpr(tt.opts)
+		if expr != tt.expr || ok != tt.ok {
+			t.Errorf("validateExpr(%q) = %q, %v; want %q, %v", tt.opts, expr, ok, tt.expr, tt.ok)
+		}
+	}
+}
+
+func TestMergeValidate(t *testing.T) {
+	RegisterValidator("upper", func(v reflect.Value) error {
+		if v.String() != strings.ToUpper(v.String()) {
+			return errors.New("not upper case")
+		}
+		return nil
+	})
+	defer RegisterValidator("upper", nil)
+
+	dst := Rating{Score: 10, Title: "old", Code: "A", Tags: []string{"a"}}
+	if err := Merge(&dst, Rating{Score: 100, Code: "B", Tags: []string{"b"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Rating{Score: 100, Title: "old", Code: "B", Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("got %+v, want %+v", dst, expected)
+	}
+
+	err := Merge(&dst, Rating{Score: 150, Code: "C"})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Path != "Score" ||
+		validationErr.Expr != "min=0,max=100" || validationErr.Value != 150 {
+		t.Fatalf("expected a ValidationError for Score, got %v", err)
+	}
+	if dst.Score != 100 {
+		t.Errorf("rejected value was assigned: %d", dst.Score)
+	}
+
+	// Under ContinueOnError every rejected value is reported.
+	err = Merge(&dst, Rating{Score: -1, Code: "d", Tags: []string{"c", "d", "e"}}, Config{ContinueOnError: true})
+	var errs MultiError
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", err)
+	}
+	if dst.Score != 100 || dst.Code != "B" || len(dst.Tags) != 2 {
+		t.Errorf("rejected values were assigned: %+v", dst)
+	}
+
+	// The result of an append is validated, not the source value alone.
+	err = Merge(&dst, Rating{Code: "B", Tags: []string{"c"}})
+	if !errors.As(err, &validationErr) || validationErr.Path != "Tags" ||
+		!reflect.DeepEqual(validationErr.Value, []string{"a", "b", "c"}) {
+		t.Fatalf("expected a ValidationError for Tags, got %v", err)
+	}
+	if !reflect.DeepEqual(dst.Tags, []string{"a", "b"}) {
+		t.Errorf("rejected result was assigned: %v", dst.Tags)
+	}
+}
+
+func TestMergeValidateUnknown(t *testing.T) {
+	type Unknown struct {
+		Name string `merge:"validate=email"`
+	}
+
+	var dst Unknown
+	err := Merge(&dst, Unknown{Name: "bob"})
+	if err == nil || !strings.Contains(err.Error(), `unknown validator "email"`) {
+		t.Errorf("expected an unknown validator error, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package structmerge
+
+import (
+	"errors"
+	"testing"
+)
+
+type Replica struct {
+	Version uint64
+	Name    string
+	Count   int
+}
+
+func TestMergeWithVersion(t *testing.T) {
+	dst := Replica{Name: "dst", Count: 3}
+	src := Replica{Name: "src"}
+
+	tests := []struct {
+		name           string
+		dstVer, srcVer int64
+		cfg            Config
+		expected       Replica
+	}{
+		{"Newer source wins", 1, 2, Config{Option: ExcludeEmpty}, Replica{Name: "src"}},
+		{"Older source skipped", 2, 1, Config{}, Replica{Name: "dst", Count: 3}},
+		{"Equal versions use the option", 2, 2, Config{Option: ExcludeEmpty}, Replica{Name: "src", Count: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dst
+			if err := MergeWithVersion(&got, src, tt.dstVer, tt.srcVer, tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("MergeWithVersion() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeWithVersionField(t *testing.T) {
+	cfg := Config{VersionField: "Version", Option: ExcludeEmpty}
+
+	got := Replica{Version: 2, Name: "dst", Count: 3}
+	if err := MergeWithVersion(&got, Replica{Version: 3, Name: "src"}, 0, 0, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (Replica{Version: 3, Name: "src"}); got != want {
+		t.Errorf("MergeWithVersion() = %+v, want %+v", got, want)
+	}
+
+	// The versions passed as arguments are ignored.
+	if err := MergeWithVersion(&got, Replica{Version: 1, Name: "stale"}, 0, 9, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "src" {
+		t.Errorf("expected the stale source to be skipped, got %+v", got)
+	}
+
+	err := MergeWithVersion(&got, Replica{}, 0, 0, Config{VersionField: "Name"})
+	var fieldErr *FieldError
+	if !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &fieldErr) || fieldErr.Path != "Name" {
+		t.Errorf("expected ErrTypeMismatch at Name, got %v", err)
+	}
+	if err := MergeWithVersion(&got, Replica{}, 0, 0, Config{VersionField: "Revision"}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got %v", err)
+	}
+	if err := MergeWithVersion(got, Replica{}, 0, 0, cfg); err != ErrInvalidDestination {
+		t.Errorf("expected ErrInvalidDestination, got %v", err)
+	}
+}
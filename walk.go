@@ -0,0 +1,60 @@
+package structmerge
+
+import "reflect"
+
+// WalkFields calls fn for every leaf field of the struct src, or of the
+// struct a pointer src points to, in declaration order. Paths follow the
+// notation of Config.Include. Nested structs and non-nil pointers to structs
+// are walked into, while time.Time values, Merger implementations and
+// nullable database types are leaves. Other non-nil pointers are
+// dereferenced, so fn receives the value they point to; nil pointers, and
+// pointers back to a struct being walked, are passed as they are.
+// Unexported fields and fields tagged with `merge:"-"` are skipped.
+//
+// Walking stops at the first error returned by fn, which WalkFields returns.
+func WalkFields(src interface{}, fn func(path string, val reflect.Value) error) error {
+	v, err := structValue(src)
+	if err != nil {
+		return err
+	}
+
+	w := walker{fn: fn, visiting: make(map[uintptr]bool)}
+	if rv := reflect.ValueOf(src); rv.Kind() == reflect.Ptr {
+		w.visiting[rv.Pointer()] = true
+	}
+	return w.walkStruct(v, "")
+}
+
+type walker struct {
+	fn       func(path string, val reflect.Value) error
+	visiting map[uintptr]bool // pointers being walked
+}
+
+func (w *walker) walkStruct(v reflect.Value, prefix string) error {
+	for _, field := range cachedMeta(v.Type()).fields {
+		if !field.exported {
+			continue
+		}
+		if err := w.walkField(v.FieldByIndex(field.index), prefix+field.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkField(v reflect.Value, path string) error {
+	switch {
+	case isNestedStruct(v.Type()):
+		return w.walkStruct(v, path+".")
+	case v.Kind() != reflect.Ptr || v.IsNil():
+		return w.fn(path, v)
+	}
+
+	addr := v.Pointer()
+	if w.visiting[addr] {
+		return w.fn(path, v)
+	}
+	w.visiting[addr] = true
+	defer delete(w.visiting, addr)
+	return w.walkField(v.Elem(), path)
+}
@@ -0,0 +1,80 @@
+package structmerge
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type WalkedEvent struct {
+	Title    string
+	Start    time.Time
+	Host     *Person
+	Backup   *Person
+	Capacity *int
+	Venue    Address
+	Next     *WalkedEvent
+	internal string
+}
+
+func TestWalkFields(t *testing.T) {
+	capacity := 50
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	ev := &WalkedEvent{
+		Title:    "launch",
+		Start:    start,
+		Host:     &Person{Name: "Alice", Address: &Address{City: "Kampala"}},
+		Capacity: &capacity,
+		Venue:    Address{Street: "Main St"},
+	}
+	ev.Next = ev
+
+	var paths []string
+	values := map[string]interface{}{}
+	err := WalkFields(ev, func(path string, val reflect.Value) error {
+		paths = append(paths, path)
+		values[path] = val.Interface()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"Title", "Start",
+		"Host.Name", "Host.Age", "Host.Address.Street", "Host.Address.City", "Host.Address.Country", "Host.Active",
+		"Backup", "Capacity",
+		"Venue.Street", "Venue.City", "Venue.Country",
+		"Next",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("paths =\n%v\nwant\n%v", paths, expected)
+	}
+
+	// time.Time is a leaf, pointers are dereferenced and cycles stop.
+	if values["Start"] != start || values["Capacity"] != 50 || values["Host.Address.City"] != "Kampala" {
+		t.Errorf("unexpected values %v", values)
+	}
+	if values["Backup"] != (*Person)(nil) || values["Next"] != ev {
+		t.Errorf("unexpected pointer values %v, %v", values["Backup"], values["Next"])
+	}
+
+	// An error stops the walk.
+	stop := errors.New("stop")
+	var visited int
+	err = WalkFields(*ev, func(path string, val reflect.Value) error {
+		visited++
+		if path == "Start" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || visited != 2 {
+		t.Errorf("expected the walk to stop after Start, got %v after %d fields", err, visited)
+	}
+
+	if err := WalkFields(42, func(string, reflect.Value) error { return nil }); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("expected ErrInvalidSource, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package structmerge
+
+import (
+	"reflect"
+	"sync"
+)
+
+// zeroCheckers maps a reflect.Type to its registered func(reflect.Value) bool.
+var zeroCheckers sync.Map
+
+// RegisterZeroChecker registers fn to decide whether values of type t are
+// empty, replacing the built-in rules for that type. It affects every option
+// and tag that checks for empty values, such as ExcludeEmpty or
+// `merge:"omitempty"`. Registering a nil fn removes the checker.
+// It is safe for concurrent use.
+//
+// For example, a UUID declared as [16]byte is empty by default when all its
+// bytes are zero. A checker can make the nil UUID count as a value instead:
+//
+//	structmerge.RegisterZeroChecker(reflect.TypeOf(uuid.UUID{}), func(v reflect.Value) bool {
+//		return false
+//	})
+func RegisterZeroChecker(t reflect.Type, fn func(v reflect.Value) bool) {
+	if fn == nil {
+		zeroCheckers.Delete(t)
+		return
+	}
+	zeroCheckers.Store(t, fn)
+}
+
+// zeroChecker returns the checker registered for t, if any.
+func zeroChecker(t reflect.Type) (func(v reflect.Value) bool, bool) {
+	fn, ok := zeroCheckers.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return fn.(func(v reflect.Value) bool), true
+}
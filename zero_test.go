@@ -0,0 +1,68 @@
+package structmerge
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type UUID [16]byte
+
+type Record struct {
+	ID   UUID
+	Name string
+}
+
+func TestRegisterZeroChecker(t *testing.T) {
+	dst := Record{ID: UUID{1}, Name: "Alice"}
+	src := Record{Name: "Bob"}
+
+	// Without a checker a zero UUID is empty and is not copied.
+	got := dst
+	if err := Merge(&got, src, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Record{ID: UUID{1}, Name: "Bob"}
+	if got != expected {
+		t.Fatalf("Merge() = %+v, want %+v", got, expected)
+	}
+
+	// A checker treating the nil UUID as a value overrides that.
+	RegisterZeroChecker(reflect.TypeOf(UUID{}), func(v reflect.Value) bool {
+		return false
+	})
+	t.Cleanup(func() { RegisterZeroChecker(reflect.TypeOf(UUID{}), nil) })
+
+	got = dst
+	if err := Merge(&got, src, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = Record{Name: "Bob"}
+	if got != expected {
+		t.Errorf("Merge() = %+v, want %+v", got, expected)
+	}
+
+	got = Record{Name: "Alice"}
+	if err := Merge(&got, Record{ID: UUID{2}}, Config{Option: OverwriteEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = Record{Name: "Alice"}
+	if got != expected {
+		t.Errorf("Merge() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestRegisterZeroCheckerDuration(t *testing.T) {
+	RegisterZeroChecker(reflect.TypeOf(time.Duration(0)), func(v reflect.Value) bool {
+		return false
+	})
+	t.Cleanup(func() { RegisterZeroChecker(reflect.TypeOf(time.Duration(0)), nil) })
+
+	dst := RetryPolicy{Delay: time.Second}
+	if err := Merge(&dst, RetryPolicy{}, Config{Option: ExcludeEmpty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Delay != 0 {
+		t.Errorf("Delay = %v, want 0", dst.Delay)
+	}
+}